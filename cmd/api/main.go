@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"hayoon-bite-backend/internal/database"
 	"hayoon-bite-backend/internal/handlers"
@@ -9,6 +10,7 @@ import (
 	"hayoon-bite-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/template/html/v2"
 	"github.com/joho/godotenv"
@@ -96,6 +98,15 @@ func main() {
 		}, "layouts/admin")
 	})
 
+	// LAYAR DAPUR
+	app.Get("/admin/kitchen", func(c *fiber.Ctx) error {
+		return c.Render("admin/kitchen", fiber.Map{
+			"Title":           "Layar Dapur",
+			"PageTitle":       "Layar Dapur",
+			"PageDescription": "Pesanan yang harus dibuat, diperbarui secara live",
+		}, "layouts/admin")
+	})
+
 	// PENGGUNA
 	app.Get("/admin/users", func(c *fiber.Ctx) error {
 		return c.Render("admin/users", fiber.Map{
@@ -128,6 +139,17 @@ func main() {
 		return c.JSON(fiber.Map{"status": "Running", "message": "API Ready"})
 	})
 	api.Post("/login", authHandler.Login)
+	// Layar antrean untuk pelanggan, hanya berisi nomor antrean
+	api.Get("/orders/queue", handlers.GetOrderQueue(database.DB))
+
+	// Pemesanan online untuk diambil di toko (tanpa login)
+	public := api.Group("/public")
+	public.Get("/menu", handlers.GetPublicMenu(database.DB))
+	public.Post("/orders", limiter.New(limiter.Config{Max: 10, Expiration: time.Minute}), handlers.CreateOnlineOrder(database.DB))
+	public.Get("/orders/:code", handlers.GetPublicOnlineOrder(database.DB))
+
+	// Live event stream (SSE); didaftarkan sebelum api.Use supaya token boleh lewat query
+	api.Get("/events", middleware.TokenFromQuery(), middleware.JWTProtected(), handlers.StreamEvents())
 
 	// === PROTECTED ROUTES (JWT) ===
 	api.Use(middleware.JWTProtected())
@@ -142,6 +164,12 @@ func main() {
 	admin.Get("/users", handlers.GetUsers(database.DB))
 	admin.Put("/users/:id", handlers.UpdateUser(database.DB))
 	admin.Delete("/users/:id", handlers.DeleteUser(database.DB))
+	admin.Get("/settings", handlers.GetSettings(database.DB))
+	admin.Put("/settings/:key", handlers.UpdateSetting(database.DB))
+	admin.Get("/tax-rules", handlers.GetTaxRules(database.DB))
+	admin.Post("/tax-rules", handlers.CreateTaxRule(database.DB))
+	admin.Put("/tax-rules/:id", handlers.UpdateTaxRule(database.DB))
+	admin.Delete("/tax-rules/:id", handlers.DeleteTaxRule(database.DB))
 
 	// Inventory Routes
 	inventory := api.Group("/inventory")
@@ -149,16 +177,78 @@ func main() {
 	inventory.Post("/stock-in", handlers.StockIn)
 	inventory.Post("", handlers.CreateInventoryItem(database.DB))
 	inventory.Put("/:id", handlers.UpdateInventoryItem(database.DB))
+	inventory.Get("/:id/movements", handlers.GetInventoryMovements(database.DB))
 	inventory.Delete("/:id", handlers.DeleteInventoryItem(database.DB))
 
+	// Stock Opname Routes
+	stockCounts := api.Group("/stock-counts")
+	stockCounts.Use(middleware.RoleProtected(models.RoleAdmin, models.RoleKaryawan))
+	stockCounts.Get("", handlers.GetStockCounts(database.DB))
+	stockCounts.Post("", handlers.StartStockCount(database.DB))
+	stockCounts.Get("/:id", handlers.GetStockCount(database.DB))
+	stockCounts.Put("/:id/items", handlers.RecordStockCountItems(database.DB))
+	stockCounts.Post("/:id/approve", middleware.RoleProtected(models.RoleAdmin), handlers.ApproveStockCount(database.DB))
+	stockCounts.Post("/:id/cancel", handlers.CancelStockCount(database.DB))
+
+	// Waste Routes (semua staf boleh mencatat)
+	waste := api.Group("/waste")
+	waste.Use(middleware.RoleProtected(models.RoleAdmin, models.RoleKaryawan, models.RoleKasir))
+	waste.Get("", handlers.GetWasteRecords(database.DB))
+	waste.Post("", handlers.CreateWasteRecord(database.DB))
+
+	// Purchasing Routes
+	suppliers := api.Group("/suppliers")
+	suppliers.Use(middleware.RoleProtected(models.RoleAdmin, models.RoleKaryawan))
+	suppliers.Get("", handlers.GetSuppliers(database.DB))
+	suppliers.Post("", handlers.CreateSupplier(database.DB))
+	suppliers.Put("/:id", handlers.UpdateSupplier(database.DB))
+	suppliers.Delete("/:id", handlers.DeleteSupplier(database.DB))
+
+	purchaseOrders := api.Group("/purchase-orders")
+	purchaseOrders.Use(middleware.RoleProtected(models.RoleAdmin, models.RoleKaryawan))
+	purchaseOrders.Get("", handlers.GetPurchaseOrders(database.DB))
+	purchaseOrders.Post("", handlers.CreatePurchaseOrder(database.DB))
+	purchaseOrders.Get("/:id", handlers.GetPurchaseOrder(database.DB))
+	purchaseOrders.Post("/:id/receive", handlers.ReceivePurchaseOrder(database.DB))
+	purchaseOrders.Post("/:id/cancel", handlers.CancelPurchaseOrder(database.DB))
+
 	// Product Routes (Admin)
 	products := api.Group("/products")
 	products.Get("", handlers.GetProducts(database.DB))
 	products.Post("", handlers.CreateProduct(database.DB))
+	// Kategori didaftarkan sebelum "/:id" supaya "categories" tidak dianggap ID
+	products.Get("/categories", handlers.GetProductCategories(database.DB))
+	products.Post("/categories", handlers.CreateProductCategory(database.DB))
+	products.Put("/categories/:id", handlers.UpdateProductCategory(database.DB))
+	products.Delete("/categories/:id", handlers.DeleteProductCategory(database.DB))
 	products.Get("/:id", handlers.GetProduct(database.DB))
+	products.Get("/:id/modifiers", handlers.GetProductModifiers(database.DB))
+	products.Put("/:id/modifiers", handlers.UpdateProductModifiers(database.DB))
 	products.Put("/:id", handlers.UpdateProduct(database.DB))
 	products.Delete("/:id", handlers.DeleteProduct(database.DB))
 
+	// Promotion & Voucher Routes (Admin)
+	promotions := api.Group("/promotions")
+	promotions.Use(middleware.RoleProtected(models.RoleAdmin))
+	promotions.Get("", handlers.GetPromotions(database.DB))
+	promotions.Post("", handlers.CreatePromotion(database.DB))
+	promotions.Put("/:id", handlers.UpdatePromotion(database.DB))
+	promotions.Delete("/:id", handlers.DeletePromotion(database.DB))
+
+	vouchers := api.Group("/vouchers")
+	vouchers.Use(middleware.RoleProtected(models.RoleAdmin))
+	vouchers.Get("", handlers.GetVouchers(database.DB))
+	vouchers.Post("", handlers.CreateVouchers(database.DB))
+	vouchers.Put("/:id", handlers.UpdateVoucher(database.DB))
+	vouchers.Delete("/:id", handlers.DeleteVoucher(database.DB))
+
+	// Payment Methods: semua user boleh melihat, hanya admin yang mengelola
+	paymentMethods := api.Group("/payment-methods")
+	paymentMethods.Get("", handlers.GetPaymentMethods(database.DB))
+	paymentMethods.Post("", middleware.RoleProtected(models.RoleAdmin), handlers.CreatePaymentMethod(database.DB))
+	paymentMethods.Put("/:id", middleware.RoleProtected(models.RoleAdmin), handlers.UpdatePaymentMethod(database.DB))
+	paymentMethods.Delete("/:id", middleware.RoleProtected(models.RoleAdmin), handlers.DeletePaymentMethod(database.DB))
+
 	// Operational Costs Routes (Admin)
 	// PASTIKAN FILE handlers/operational_costs.go SUDAH DIBUAT
 	// JIKA BELUM, BAGIAN INI AKAN ERROR DI TERMINAL
@@ -171,12 +261,52 @@ func main() {
 	// POS Routes
 	pos := api.Group("/pos")
 	pos.Use(middleware.RoleProtected(models.RoleKasir, models.RoleAdmin))
+	pos.Post("/shifts/open", handlers.OpenShift(database.DB))
+	pos.Get("/shifts/current", handlers.GetCurrentShift(database.DB))
+	pos.Get("/shifts", handlers.GetShifts(database.DB))
+	pos.Get("/shifts/:id", handlers.GetShift(database.DB))
+	pos.Post("/shifts/:id/close", handlers.CloseShift(database.DB))
 	pos.Post("/transactions", handlers.CreateTransaction)
+	pos.Post("/transactions/sync", handlers.SyncTransactions(database.DB))
+	pos.Get("/transactions", handlers.GetTransactions(database.DB))
+	pos.Get("/transactions/:id", handlers.GetTransaction(database.DB))
+	pos.Get("/transactions/:id/receipt", handlers.GetTransactionReceipt(database.DB))
+	pos.Post("/transactions/:id/void", handlers.VoidTransaction(database.DB))
+	pos.Post("/transactions/:id/refund", handlers.RefundTransaction(database.DB))
+
+	// Customer & Loyalty Routes
+	customers := api.Group("/customers")
+	customers.Use(middleware.RoleProtected(models.RoleKasir, models.RoleAdmin))
+	customers.Get("", handlers.GetCustomers(database.DB))
+	customers.Post("", handlers.CreateCustomer(database.DB))
+	customers.Get("/:id", handlers.GetCustomer(database.DB))
+	customers.Put("/:id", handlers.UpdateCustomer(database.DB))
+	customers.Get("/:id/history", handlers.GetCustomerHistory(database.DB))
+	customers.Post("/:id/points", middleware.RoleProtected(models.RoleAdmin), handlers.AdjustCustomerPoints(database.DB))
+
+	// Online Order Routes (konfirmasi/penolakan oleh staf)
+	onlineOrders := api.Group("/online-orders")
+	onlineOrders.Use(middleware.RoleProtected(models.RoleKasir, models.RoleAdmin))
+	onlineOrders.Get("", handlers.GetOnlineOrders(database.DB))
+	onlineOrders.Get("/:id", handlers.GetOnlineOrder(database.DB))
+	onlineOrders.Post("/:id/confirm", handlers.ConfirmOnlineOrder(database.DB))
+	onlineOrders.Post("/:id/reject", handlers.RejectOnlineOrder(database.DB))
+
+	// Kitchen Display Routes
+	kitchen := api.Group("/kitchen")
+	kitchen.Use(middleware.RoleProtected(models.RoleKaryawan, models.RoleKasir, models.RoleAdmin))
+	kitchen.Get("/orders", handlers.GetKitchenOrders(database.DB))
+	kitchen.Post("/orders/:id/status", handlers.UpdateOrderStatus(database.DB))
 
 	// Reports Routes
 	reports := api.Group("/reports")
 	reports.Use(middleware.RoleProtected(models.RoleAdmin, models.RoleKaryawan))
 	reports.Get("/financial", handlers.GetFinancialReport)
+	reports.Get("/sales", handlers.GetSalesReport(database.DB))
+	reports.Get("/profit-loss", handlers.GetProfitLossReport(database.DB))
+	reports.Get("/discounts", handlers.GetDiscountReport(database.DB))
+	reports.Get("/tax", handlers.GetTaxReport(database.DB))
+	reports.Get("/waste", handlers.GetWasteReport(database.DB))
 
 	log.Println("Server berjalan di port :8080")
 	log.Fatal(app.Listen(":8080"))
@@ -1,21 +1,87 @@
 package main
 
 import (
+	"fmt"
 	"hayoon-bite-backend/internal/database"
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
+const usage = `Usage: go run ./cmd/migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [N]    roll back the last N migrations (default 1)
+  status      list migrations and whether they are applied
+  goto N      migrate up or down to version N (0 = roll back everything)
+  seed        insert initial data (idempotent)`
+
 func main() {
 	// 1. Load env
 	if err := godotenv.Load(); err != nil {
 		log.Fatal("Error loading .env file")
 	}
 
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
 	// 2. Connect Database
 	database.Connect()
 
-	// 3. Jalankan Migrasi
-	database.Migrate()
+	// 3. Jalankan perintah migrasi
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "up":
+		err = database.MigrateUp()
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			if steps, err = strconv.Atoi(os.Args[2]); err != nil || steps < 1 {
+				log.Fatalf("❌ Invalid number of steps: %s", os.Args[2])
+			}
+		}
+		err = database.MigrateDown(steps)
+	case "goto":
+		if len(os.Args) < 3 {
+			log.Fatal("❌ Missing target version, e.g. `goto 3`")
+		}
+		version, parseErr := strconv.ParseUint(os.Args[2], 10, 32)
+		if parseErr != nil {
+			log.Fatalf("❌ Invalid version: %s", os.Args[2])
+		}
+		err = database.MigrateTo(uint(version))
+	case "status":
+		err = printStatus()
+	case "seed":
+		err = database.Seed()
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Println("✅ Done")
+}
+
+func printStatus() error {
+	statuses, err := database.MigrationStatuses()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%06d  %-30s  %s\n", s.Version, s.Name, state)
+	}
+	return nil
 }
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Lokasi file migrasi dan seed, relatif terhadap root proyek
+const (
+	MigrationsDir = "migrations"
+	SeedsDir      = "seeds"
+)
+
+// migrationFilePattern matches files like 000002_transaction_refunds.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change with its up and down SQL files
+type Migration struct {
+	Version  uint
+	Name     string
+	UpPath   string
+	DownPath string
+}
+
+// MigrationStatus tells whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row in the schema_migrations table
+type schemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations reads the migration files in dir, ordered by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir %s: %w", dir, err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has mismatching names: %s and %s", version, m.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] == "up" {
+			m.UpPath = path
+		} else {
+			m.DownPath = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpPath == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ensureMigrationsTable creates the schema_migrations table if needed
+// and returns the applied migrations keyed by version
+func ensureMigrationsTable() (map[uint]schemaMigration, error) {
+	if DB == nil {
+		Connect()
+	}
+	if err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`).Error; err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var rows []schemaMigration
+	if err := DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+
+	applied := make(map[uint]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// MigrationStatuses returns every known migration and whether it has been applied
+func MigrationStatuses() ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := ensureMigrationsTable()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if row, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies all pending migrations in order
+func MigrateUp() error {
+	migrations, err := LoadMigrations(MigrationsDir)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return MigrateTo(migrations[len(migrations)-1].Version)
+}
+
+// MigrateDown rolls back the given number of most recently applied migrations
+func MigrateDown(steps int) error {
+	statuses, err := MigrationStatuses()
+	if err != nil {
+		return err
+	}
+
+	for i := len(statuses) - 1; i >= 0 && steps > 0; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+		if err := runDown(statuses[i].Migration); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+// MigrateTo migrates the schema up or down so that exactly the migrations
+// with a version <= target are applied
+func MigrateTo(target uint) error {
+	statuses, err := MigrationStatuses()
+	if err != nil {
+		return err
+	}
+
+	known := target == 0
+	for _, s := range statuses {
+		if s.Version == target {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown migration version %d", target)
+	}
+
+	// Turunkan dulu yang lebih baru dari target (dari yang terbaru)
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].Applied && statuses[i].Version > target {
+			if err := runDown(statuses[i].Migration); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Lalu jalankan yang belum diterapkan sampai target
+	for _, s := range statuses {
+		if !s.Applied && s.Version <= target {
+			if err := runUp(s.Migration); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runUp menjalankan satu file .up.sql dan mencatatnya dalam satu transaksi
+func runUp(m Migration) error {
+	sql, err := os.ReadFile(m.UpPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", m.UpPath, err)
+	}
+
+	log.Printf("⬆️  Applying migration %06d_%s", m.Version, m.Name)
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(string(sql)).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %06d_%s failed: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// runDown menjalankan satu file .down.sql dan menghapus catatannya dalam satu transaksi
+func runDown(m Migration) error {
+	if m.DownPath == "" {
+		return fmt.Errorf("migration %06d_%s has no .down.sql file", m.Version, m.Name)
+	}
+	sql, err := os.ReadFile(m.DownPath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", m.DownPath, err)
+	}
+
+	log.Printf("⬇️  Reverting migration %06d_%s", m.Version, m.Name)
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(string(sql)).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, m.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("rollback %06d_%s failed: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// Seed runs every .sql file in the seeds directory in name order.
+// File seed harus idempotent (ON CONFLICT / NOT EXISTS) karena bisa dijalankan berkali-kali.
+func Seed() error {
+	if DB == nil {
+		Connect()
+	}
+
+	files, err := filepath.Glob(filepath.Join(SeedsDir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+
+		log.Printf("🌱 Seeding %s", file)
+		if err := DB.Exec(string(sql)).Error; err != nil {
+			return fmt.Errorf("seed %s failed: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// pointsDiscountName adalah nama diskon di transaksi untuk penukaran poin
+const pointsDiscountName = "Tukar Poin"
+
+// CustomerRequest defines the body for creating/updating a customer
+type CustomerRequest struct {
+	Phone string `json:"phone"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// validate normalizes the phone number and checks the fields
+func (r *CustomerRequest) validate() error {
+	r.Phone = services.NormalizePhone(r.Phone)
+	r.Name = strings.TrimSpace(r.Name)
+	r.Email = strings.TrimSpace(r.Email)
+
+	if len(r.Phone) < 8 || len(r.Phone) > 15 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid phone number")
+	}
+	if r.Name == "" || len(r.Name) > 100 {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters")
+	}
+	if r.Email != "" && (len(r.Email) > 255 || !strings.Contains(r.Email, "@")) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid email")
+	}
+	return nil
+}
+
+// GetCustomers handles searching customers by ?phone= (nomor lengkap) atau ?search= (nama/nomor)
+func GetCustomers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Order("name, id").Limit(50)
+		if phone := c.Query("phone"); phone != "" {
+			query = query.Where("phone = ?", services.NormalizePhone(phone))
+		}
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			condition, args := "name ILIKE ?", []interface{}{"%" + search + "%"}
+			if phone := services.NormalizePhone(search); phone != "" {
+				condition += " OR phone LIKE ?"
+				args = append(args, "%"+phone+"%")
+			}
+			query = query.Where("("+condition+")", args...)
+		}
+
+		customers := []models.Customer{}
+		if err := query.Find(&customers).Error; err != nil {
+			return respondError(c, err, "Failed to fetch customers")
+		}
+		return c.JSON(customers)
+	}
+}
+
+// GetCustomer handles fetching a single customer
+func GetCustomer(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid customer ID"})
+		}
+		var customer models.Customer
+		if err := db.First(&customer, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Customer not found"})
+		}
+		return c.JSON(customer)
+	}
+}
+
+// CreateCustomer handles registering a customer, usually at the POS by phone number
+func CreateCustomer(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req CustomerRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if err := req.validate(); err != nil {
+			return respondError(c, err, "Invalid customer")
+		}
+
+		// Nomor sudah terdaftar: kembalikan pelanggan yang ada supaya kasir bisa langsung memakainya
+		var existing models.Customer
+		if err := db.Where("phone = ?", req.Phone).First(&existing).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    "A customer with this phone number already exists",
+				"customer": existing,
+			})
+		}
+
+		customer := models.Customer{Phone: req.Phone, Name: req.Name, Email: req.Email}
+		if err := db.Create(&customer).Error; err != nil {
+			return respondError(c, err, "Failed to create customer")
+		}
+		return c.Status(fiber.StatusCreated).JSON(customer)
+	}
+}
+
+// UpdateCustomer handles updating a customer's details (saldo poin tidak bisa diubah di sini)
+func UpdateCustomer(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid customer ID"})
+		}
+		var req CustomerRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if err := req.validate(); err != nil {
+			return respondError(c, err, "Invalid customer")
+		}
+
+		var customer models.Customer
+		if err := db.First(&customer, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Customer not found"})
+		}
+		var count int64
+		db.Model(&models.Customer{}).Where("phone = ? AND id <> ?", req.Phone, customer.ID).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A customer with this phone number already exists"})
+		}
+
+		customer.Phone = req.Phone
+		customer.Name = req.Name
+		customer.Email = req.Email
+		if err := db.Select("phone", "name", "email", "updated_at").Save(&customer).Error; err != nil {
+			return respondError(c, err, "Failed to update customer")
+		}
+		return c.JSON(customer)
+	}
+}
+
+// CustomerFavourite is a product the customer buys often
+type CustomerFavourite struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	Visits      int    `json:"visits"` // jumlah transaksi yang memuat produk ini
+}
+
+// GetCustomerHistory handles the purchase history of a customer: visits, spend,
+// favourite products, recent transactions and point changes.
+// Filter opsional: start_date/end_date (YYYY-MM-DD) untuk ringkasan dan produk favorit.
+func GetCustomerHistory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid customer ID"})
+		}
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		var customer models.Customer
+		if err := db.First(&customer, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Customer not found"})
+		}
+
+		// Transaksi yang di-void tidak dihitung sebagai kunjungan
+		sales := func() *gorm.DB {
+			query := db.Table("transactions t").Where("t.customer_id = ? AND t.status <> ?", customer.ID, models.TransactionStatusVoided)
+			return whereDateRange(query, "t.transaction_time", startDate, endDate)
+		}
+
+		var summary struct {
+			Visits     int        `json:"visits"`
+			TotalSpend float64    `json:"total_spend"` // setelah refund
+			FirstVisit *time.Time `json:"first_visit"`
+			LastVisit  *time.Time `json:"last_visit"`
+		}
+		err = sales().
+			Select("count(*) as visits, coalesce(sum(t.total_amount - t.refunded_amount), 0) as total_spend, min(t.transaction_time) as first_visit, max(t.transaction_time) as last_visit").
+			Scan(&summary).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch customer history")
+		}
+		averageSpend := 0.0
+		if summary.Visits > 0 {
+			averageSpend = services.RoundMoney(summary.TotalSpend / float64(summary.Visits))
+		}
+
+		favourites := []CustomerFavourite{}
+		err = sales().
+			Select("ti.product_id, max(ti.product_name) as product_name, sum(ti.quantity - ti.refunded_quantity) as quantity, count(distinct t.id) as visits").
+			Joins("join transaction_items ti on ti.transaction_id = t.id").
+			Group("ti.product_id").
+			Having("sum(ti.quantity - ti.refunded_quantity) > 0").
+			Order("quantity desc, visits desc").
+			Limit(5).
+			Scan(&favourites).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch customer history")
+		}
+
+		transactions := []models.Transaction{}
+		err = db.Where("customer_id = ?", customer.ID).
+			Order("transaction_time desc, id desc").
+			Limit(20).
+			Find(&transactions).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch customer history")
+		}
+
+		points := []models.LoyaltyPointEntry{}
+		if err := db.Where("customer_id = ?", customer.ID).Order("id desc").Limit(20).Find(&points).Error; err != nil {
+			return respondError(c, err, "Failed to fetch customer history")
+		}
+
+		return c.JSON(fiber.Map{
+			"customer":            customer,
+			"visits":              summary.Visits,
+			"total_spend":         summary.TotalSpend,
+			"average_spend":       averageSpend,
+			"first_visit":         summary.FirstVisit,
+			"last_visit":          summary.LastVisit,
+			"favourite_products":  favourites,
+			"recent_transactions": transactions,
+			"recent_points":       points,
+		})
+	}
+}
+
+// AdjustPointsRequest defines a manual change to a customer's points
+type AdjustPointsRequest struct {
+	Points int    `json:"points"` // negatif untuk mengurangi
+	Note   string `json:"note"`
+}
+
+// AdjustCustomerPoints handles an admin correcting or rewarding a customer's points
+func AdjustCustomerPoints(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid customer ID"})
+		}
+		var req AdjustPointsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		req.Note = strings.TrimSpace(req.Note)
+		if req.Points == 0 || req.Note == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Points and note are required"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var customer models.Customer
+		err = db.Transaction(func(tx *gorm.DB) error {
+			_, err := services.NewLoyaltyService(tx).AddPoints(models.LoyaltyPointEntry{
+				CustomerID: uint(id),
+				Type:       models.LoyaltyEntryAdjust,
+				Points:     req.Points,
+				Note:       req.Note,
+				UserID:     &userID,
+			})
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Customer not found")
+			}
+			if err != nil {
+				return err
+			}
+			return tx.First(&customer, id).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to adjust points")
+		}
+		return c.JSON(customer)
+	}
+}
+
+// pointsDiscount returns the discount for redeeming points on a sale whose
+// remaining amount (setelah promo dan voucher) is remaining
+func pointsDiscount(rules services.LoyaltyRules, customer models.Customer, points int, remaining float64) (float64, error) {
+	if rules.PointValue <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Point redemption is disabled")
+	}
+	if points < rules.MinRedeem {
+		return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Redeem at least %d points", rules.MinRedeem))
+	}
+	if points > customer.PointsBalance {
+		return 0, services.ErrInsufficientPoints
+	}
+	amount := services.RoundMoney(float64(points) * rules.PointValue)
+	// Poin yang nilainya dibulatkan jadi Rp0 tidak boleh hangus percuma, dan
+	// amount > 0 menjamin remaining > 0 untuk pembagian ke tiap baris
+	if amount <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Redeemed points are worth less than the smallest discount")
+	}
+	if amount > remaining {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Redeemed points exceed the amount to pay")
+	}
+	return amount, nil
+}
+
+// recordSalePoints writes the redeemed and earned points of a new sale to the
+// customer's ledger. Poin yang ditukar dipotong dulu, saldo dikunci di LoyaltyService.
+func recordSalePoints(tx *gorm.DB, transaction models.Transaction, userID uint) error {
+	loyalty := services.NewLoyaltyService(tx)
+	note := fmt.Sprintf("Transaksi #%d", transaction.ID)
+	if transaction.PointsRedeemed > 0 {
+		if _, err := loyalty.AddPoints(models.LoyaltyPointEntry{
+			CustomerID:    *transaction.CustomerID,
+			TransactionID: &transaction.ID,
+			Type:          models.LoyaltyEntryRedeem,
+			Points:        -transaction.PointsRedeemed,
+			Note:          note,
+			UserID:        &userID,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := loyalty.AddPoints(models.LoyaltyPointEntry{
+		CustomerID:    *transaction.CustomerID,
+		TransactionID: &transaction.ID,
+		Type:          models.LoyaltyEntryEarn,
+		Points:        transaction.PointsEarned,
+		Note:          note,
+		UserID:        &userID,
+	})
+	return err
+}
+
+// reverseSalePoints gives back the redeemed points and takes back the earned points
+// for the part of a sale that is cancelled, i.e. between the refunded fractions
+// before and after (void: 0 sampai 1). Dihitung dari selisih pembulatan kumulatif
+// supaya total pembalikan setelah beberapa refund parsial tetap tepat.
+func reverseSalePoints(tx *gorm.DB, transaction models.Transaction, entryType models.LoyaltyEntryType, before, after float64, userID uint, note string) error {
+	if transaction.CustomerID == nil {
+		return nil
+	}
+	portion := func(points int) int {
+		return int(math.Round(float64(points)*after)) - int(math.Round(float64(points)*before))
+	}
+
+	loyalty := services.NewLoyaltyService(tx)
+	if _, err := loyalty.AddPoints(models.LoyaltyPointEntry{
+		CustomerID:    *transaction.CustomerID,
+		TransactionID: &transaction.ID,
+		Type:          entryType,
+		Points:        portion(transaction.PointsRedeemed),
+		Note:          note,
+		UserID:        &userID,
+	}); err != nil {
+		return err
+	}
+	_, err := loyalty.ReversePoints(models.LoyaltyPointEntry{
+		CustomerID:    *transaction.CustomerID,
+		TransactionID: &transaction.ID,
+		Type:          entryType,
+		Points:        -portion(transaction.PointsEarned),
+		Note:          note,
+		UserID:        &userID,
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// eventKeepAlive mengirim komentar kosong secara berkala supaya proxy tidak menutup
+// koneksi dan koneksi klien yang sudah putus cepat terdeteksi
+const eventKeepAlive = 25 * time.Second
+
+// StreamEvents handles the live event stream (Server-Sent Events).
+//
+// Event yang dikirim disaring berdasarkan role pemanggil; gunakan
+// ?types=order.status_changed,stock.low untuk hanya menerima jenis tertentu.
+// EventSource di browser tidak bisa mengirim header, jadi token boleh lewat ?token=.
+func StreamEvents() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		_, role, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var types map[services.EventType]bool
+		if param := c.Query("types"); param != "" {
+			types = make(map[services.EventType]bool)
+			for _, t := range strings.Split(param, ",") {
+				types[services.EventType(strings.TrimSpace(t))] = true
+			}
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no") // matikan buffering nginx
+
+		events, unsubscribe := services.Events.Subscribe()
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer unsubscribe()
+
+			keepAlive := time.NewTicker(eventKeepAlive)
+			defer keepAlive.Stop()
+
+			fmt.Fprint(w, "retry: 3000\n\n")
+			if err := w.Flush(); err != nil {
+				return
+			}
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if !event.VisibleTo(role) || (types != nil && !types[event.Type]) {
+						continue
+					}
+					data, err := json.Marshal(event)
+					if err != nil {
+						log.Printf("Error encoding event %s: %v", event.Type, err)
+						continue
+					}
+					fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+				case <-keepAlive.C:
+					fmt.Fprint(w, ": ping\n\n")
+				}
+				// Flush gagal berarti klien sudah menutup koneksi
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
+
+// publishSaleEvents publishes the events of a recorded sale; transaction harus
+// sudah di-load lengkap dengan item dan modifier
+func publishSaleEvents(transaction models.Transaction, stockItems []models.InventoryItem) {
+	services.Events.Publish(services.EventTransactionCreated, transaction)
+	services.Events.Publish(services.EventOrderStatusChanged, toKitchenOrder(transaction))
+	services.Events.PublishStockLevels(stockItems)
+}
+
+// publishOrderEvent publishes the current kitchen state of an order
+func publishOrderEvent(db *gorm.DB, transactionID uint) {
+	var transaction models.Transaction
+	err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&transaction, transactionID).Error
+	if err != nil {
+		log.Printf("Error loading order %d for event: %v", transactionID, err)
+		return
+	}
+	services.Events.Publish(services.EventOrderStatusChanged, toKitchenOrder(transaction))
+}
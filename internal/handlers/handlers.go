@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"hayoon-bite-backend/internal/database"
+	"hayoon-bite-backend/internal/middleware"
 	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 func GetInventory(c *fiber.Ctx) error {
@@ -18,6 +24,8 @@ func GetInventory(c *fiber.Ctx) error {
 type StockInRequest struct {
 	ID       uint    `json:"id"`
 	Quantity float64 `json:"quantity"`
+	// Harga beli per unit (opsional), dipakai untuk menghitung ulang cost_per_unit
+	UnitPrice float64 `json:"unit_price"`
 }
 
 func StockIn(c *fiber.Ctx) error {
@@ -25,24 +33,47 @@ func StockIn(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
+	if req.Quantity <= 0 || req.UnitPrice < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Quantity must be greater than zero and unit price cannot be negative"})
+	}
 
-	var item models.InventoryItem
-	if err := database.DB.First(&item, req.ID).Error; err != nil {
+	userID, _, _ := middleware.GetUserFromContext(c)
+	items, err := services.NewStockService(database.DB).Apply([]services.StockChange{{
+		InventoryItemID: req.ID,
+		Delta:           req.Quantity,
+		UnitCost:        req.UnitPrice,
+		MovementInfo:    services.MovementInfo{Type: models.StockMovementStockIn, UserID: &userID},
+	}})
+	if errors.Is(err, services.ErrInventoryItemNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Inventory item not found"})
 	}
+	if err != nil {
+		return respondError(c, err, "Failed to update inventory")
+	}
+	services.Events.PublishStockLevels(items)
 
-	item.StockLevel += req.Quantity
-	database.DB.Save(&item)
-
-	return c.JSON(item)
+	return c.JSON(items[0])
 }
 
 type TransactionRequest struct {
-	PaymentMethod string `json:"payment_method"`
-	Items         []struct {
-		ProductID uint `json:"product_id"`
-		Quantity  int  `json:"quantity"`
-	} `json:"items"`
+	// PaymentMethod untuk satu pembayaran penuh; Payments untuk split/multi-tender
+	PaymentMethod string           `json:"payment_method"`
+	Payments      []PaymentRequest `json:"payments"`
+	VoucherCode   string           `json:"voucher_code"`
+	// Alternatif header Idempotency-Key, mis. UUID transaksi yang dibuat klien
+	IdempotencyKey string                   `json:"idempotency_key"`
+	Items          []TransactionItemRequest `json:"items"`
+	// Pelanggan (opsional) untuk mengumpulkan poin; RedeemPoints ditukar sebagai diskon
+	CustomerID   *uint `json:"customer_id"`
+	RedeemPoints int   `json:"redeem_points"`
+}
+
+// TransactionItemRequest is one product in a checkout
+type TransactionItemRequest struct {
+	ProductID uint `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+	// Opsi modifier yang dipilih, mis. topping tambahan
+	ModifierOptionIDs []uint `json:"modifier_option_ids"`
 }
 
 func CreateTransaction(c *fiber.Ctx) error {
@@ -51,123 +82,391 @@ func CreateTransaction(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
-	tx := database.DB.Begin()
-	if tx.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to begin transaction"})
+	userID, _, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	// Request ulang dengan key yang sama mengembalikan transaksi aslinya
+	idempotencyKey := normalizeIdempotencyKey(c.Get(idempotencyKeyHeader, req.IdempotencyKey))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Idempotency key is too long"})
 	}
+	if idempotencyKey != "" {
+		existing, found, err := transactionByIdempotencyKey(database.DB, idempotencyKey)
+		if err != nil {
+			return respondError(c, err, "Failed to check idempotency key")
+		}
+		if found {
+			return replayTransaction(c, database.DB, existing, userID)
+		}
+	}
+
+	var result checkoutResult
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Setiap penjualan wajib tercatat di shift kasir yang sedang buka
+		shift, err := findOpenShift(tx, userID)
+		if err != nil {
+			return err
+		}
+		result, err = checkout(tx, req, userID, shift, time.Now(), idempotencyKey)
+		if err != nil {
+			return err
+		}
+		// Pesanan langsung masuk antrean dapur dengan nomor antrean hari ini
+		return assignQueueNumber(tx, &result.Transaction)
+	})
+	if err != nil {
+		// Request ulang yang berjalan bersamaan: unique index menahan insert ini sampai
+		// request pertama commit, lalu kembalikan transaksi yang sudah tercatat
+		if idempotencyKey != "" {
+			if existing, found, _ := transactionByIdempotencyKey(database.DB, idempotencyKey); found {
+				return replayTransaction(c, database.DB, existing, userID)
+			}
+		}
+		return respondError(c, err, "Failed to create transaction")
+	}
+
+	// Muat ulang lengkap dengan item, modifier, diskon, pajak dan pembayaran
+	saved, err := loadTransaction(database.DB, result.Transaction.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load transaction"})
+	}
+	publishSaleEvents(saved, result.StockItems)
+
+	response := fiber.Map{
+		"message":     "Transaction successful",
+		"transaction": saved,
+	}
+	if len(result.Shortages) > 0 {
+		response["warnings"] = result.Shortages
+	}
+	return c.JSON(response)
+}
+
+// maxLineQuantity membatasi jumlah unit per baris penjualan di kasir
+const maxLineQuantity = 1000
 
-	var totalAmount float64
-	for _, item := range req.Items {
+// checkoutResult is what checkout recorded: the sale, the stock shortages to warn
+// about and the inventory items whose stock changed
+type checkoutResult struct {
+	Transaction models.Transaction
+	Shortages   []services.Shortage
+	StockItems  []models.InventoryItem
+}
+
+// checkout records a sale inside tx: it prices the items and modifiers, applies the
+// promotions and tax rules in effect at soldAt, stores the transaction and deducts
+// the ingredients from stock. Dipakai oleh CreateTransaction dan sync offline,
+// sehingga keduanya selalu menghitung dan memotong stok dengan cara yang sama.
+func checkout(tx *gorm.DB, req TransactionRequest, userID uint, shift *models.Shift, soldAt time.Time, idempotencyKey string) (checkoutResult, error) {
+	if len(req.Items) == 0 {
+		return checkoutResult{}, fiber.NewError(fiber.StatusBadRequest, "Transaction must have at least one item")
+	}
+	if req.RedeemPoints < 0 {
+		return checkoutResult{}, fiber.NewError(fiber.StatusBadRequest, "redeem_points cannot be negative")
+	}
+	if req.RedeemPoints > 0 && req.CustomerID == nil {
+		return checkoutResult{}, fiber.NewError(fiber.StatusBadRequest, "A customer is required to redeem points")
+	}
+	var customer *models.Customer
+	if req.CustomerID != nil {
+		customer = &models.Customer{}
+		if err := tx.First(customer, *req.CustomerID).Error; err != nil {
+			return checkoutResult{}, fiber.NewError(fiber.StatusNotFound, "Customer not found")
+		}
+	}
+
+	var err error
+	var subtotal float64
+	products := make(map[uint]models.Product)
+	modifiers := make([][]models.ModifierOption, len(req.Items))
+	cart := make([]services.CartLine, len(req.Items))
+	for i, item := range req.Items {
+		if item.Quantity <= 0 || item.Quantity > maxLineQuantity {
+			return checkoutResult{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Quantity must be between 1 and %d", maxLineQuantity))
+		}
 		var product models.Product
 		if err := tx.First(&product, item.ProductID).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+			return checkoutResult{}, fiber.NewError(fiber.StatusNotFound, "Product not found")
+		}
+		products[product.ID] = product
+
+		modifiers[i], err = selectModifiers(tx, product.ID, item.ModifierOptionIDs)
+		if err != nil {
+			return checkoutResult{}, err
 		}
-		totalAmount += product.Price * float64(item.Quantity)
+		unitPrice := product.Price
+		for _, option := range modifiers[i] {
+			unitPrice += option.PriceDelta
+		}
+		subtotal += unitPrice * float64(item.Quantity)
+		cart[i] = services.CartLine{
+			ProductID:  product.ID,
+			CategoryID: product.CategoryID,
+			Quantity:   item.Quantity,
+			UnitPrice:  unitPrice,
+		}
+	}
+
+	// Hitung promo otomatis (mis. happy hour) dan voucher yang dimasukkan kasir
+	discounts, err := services.NewPromotionService(tx).Apply(cart, req.VoucherCode, soldAt)
+	if err != nil {
+		return checkoutResult{}, err
 	}
 
 	transaction := models.Transaction{
-		TotalAmount:   totalAmount,
-		PaymentMethod: req.PaymentMethod,
+		TransactionTime: soldAt,
+		Status:          models.TransactionStatusCompleted,
+		OrderStatus:     models.OrderStatusQueued,
+		UserID:          &userID,
+		ShiftID:         &shift.ID,
+	}
+	if idempotencyKey != "" {
+		transaction.IdempotencyKey = &idempotencyKey
+	}
+	lineDiscounts := make([]float64, len(req.Items))
+	for _, discount := range discounts {
+		applied := models.TransactionDiscount{
+			PromotionID: &discount.Promotion.ID,
+			Name:        discount.Promotion.Name,
+			Amount:      discount.Amount,
+		}
+		if discount.Voucher != nil {
+			applied.VoucherID = &discount.Voucher.ID
+			applied.Code = discount.Voucher.Code
+		}
+		transaction.Discounts = append(transaction.Discounts, applied)
+		transaction.DiscountAmount += discount.Amount
+		for i, amount := range discount.LineAmounts {
+			lineDiscounts[i] += amount
+		}
+	}
+	transaction.Subtotal = subtotal
+
+	// Tukar poin dihitung paling akhir sebagai diskon, setelah promo dan voucher
+	loyaltyRules := services.GetLoyaltyRules(tx)
+	if req.RedeemPoints > 0 {
+		remaining := subtotal - transaction.DiscountAmount
+		amount, err := pointsDiscount(loyaltyRules, *customer, req.RedeemPoints, remaining)
+		if err != nil {
+			return checkoutResult{}, err
+		}
+		transaction.Discounts = append(transaction.Discounts, models.TransactionDiscount{
+			Name:   pointsDiscountName,
+			Amount: amount,
+		})
+		transaction.DiscountAmount += amount
+		transaction.PointsRedeemed = req.RedeemPoints
+
+		// Dibagi proporsional ke sisa harga tiap baris, sisa pembulatan ke baris terakhir
+		allocated := 0.0
+		for i, line := range cart {
+			if i == len(cart)-1 {
+				lineDiscounts[i] += services.RoundMoney(amount - allocated)
+				break
+			}
+			share := services.RoundMoney(amount * (line.UnitPrice*float64(line.Quantity) - lineDiscounts[i]) / remaining)
+			lineDiscounts[i] += share
+			allocated += share
+		}
+	}
+
+	// Service charge & pajak dihitung dari harga setelah diskon
+	rules, err := services.ActiveTaxRules(tx)
+	if err != nil {
+		return checkoutResult{}, err
+	}
+	charges := services.CalculateCharges(rules, subtotal-transaction.DiscountAmount)
+	for _, charge := range charges.Charges {
+		transaction.Charges = append(transaction.Charges, models.TransactionCharge{
+			TaxRuleID: &charge.Rule.ID,
+			Name:      charge.Rule.Name,
+			Kind:      charge.Rule.Kind,
+			Rate:      charge.Rule.Rate,
+			Inclusive: charge.Rule.Inclusive,
+			Base:      charge.Base,
+			Amount:    charge.Amount,
+		})
+	}
+	transaction.ServiceChargeAmount = charges.ServiceCharge
+	transaction.TaxAmount = charges.Tax
+	transaction.TotalAmount = charges.Total
+	if customer != nil {
+		transaction.CustomerID = &customer.ID
+		transaction.PointsEarned = loyaltyRules.PointsFor(transaction.TotalAmount)
+	}
+
+	// Pembayaran harus pas dengan total tagihan (kembalian hanya untuk tunai)
+	transaction.Payments, transaction.PaymentMethod, err = buildPayments(tx, req.PaymentMethod, req.Payments, transaction.TotalAmount)
+	if err != nil {
+		return checkoutResult{}, err
 	}
 	if err := tx.Create(&transaction).Error; err != nil {
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create transaction"})
+		return checkoutResult{}, err
+	}
+	if customer != nil {
+		if err := recordSalePoints(tx, transaction, userID); err != nil {
+			return checkoutResult{}, err
+		}
 	}
 
-	for _, item := range req.Items {
-		var product models.Product
-		tx.First(&product, item.ProductID)
+	stock := services.NewStockService(tx)
+	movement := services.MovementInfo{
+		Type:          models.StockMovementSale,
+		ReferenceType: models.ReferenceTransaction,
+		ReferenceID:   &transaction.ID,
+		UserID:        &userID,
+	}
+	var stockChanges []services.StockChange
+	for i, item := range req.Items {
+		product := products[item.ProductID]
+		unitCost, err := recipeUnitCost(tx, product.ID)
+		if err != nil {
+			return checkoutResult{}, err
+		}
+
 		transactionItem := models.TransactionItem{
 			TransactionID: transaction.ID,
-			ProductID:     item.ProductID,
+			ProductID:     product.ID,
+			ProductName:   product.Name,
+			UnitPrice:     product.Price,
+			UnitCost:      unitCost,
 			Quantity:      item.Quantity,
-			Subtotal:      product.Price * float64(item.Quantity),
 		}
+		// Harga dan biaya modifier ditambahkan ke harga per unit item
+		optionIDs := make([]uint, 0, len(modifiers[i]))
+		for _, option := range modifiers[i] {
+			optionCost, err := modifierUnitCost(tx, option.ID)
+			if err != nil {
+				return checkoutResult{}, err
+			}
+			transactionItem.UnitPrice += option.PriceDelta
+			transactionItem.UnitCost += optionCost
+			transactionItem.Modifiers = append(transactionItem.Modifiers, models.TransactionItemModifier{
+				ModifierOptionID: &option.ID,
+				Name:             option.Name,
+				PriceDelta:       option.PriceDelta,
+				UnitCost:         optionCost,
+			})
+			optionIDs = append(optionIDs, option.ID)
+		}
+		transactionItem.Subtotal = transactionItem.UnitPrice * float64(item.Quantity)
+		transactionItem.DiscountAmount = lineDiscounts[i]
 		if err := tx.Create(&transactionItem).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create transaction item: " + err.Error()})
+			return checkoutResult{}, err
 		}
 
-		var recipeItems []models.RecipeItem
-		tx.Where("product_id = ?", item.ProductID).Find(&recipeItems)
+		changes, err := stock.RecipeChanges(product.ID, item.Quantity, -1, movement)
+		if err != nil {
+			return checkoutResult{}, err
+		}
+		stockChanges = append(stockChanges, changes...)
 
-		for _, recipeItem := range recipeItems {
-			var inventoryItem models.InventoryItem
-			if err := tx.First(&inventoryItem, recipeItem.InventoryItemID).Error; err != nil {
-				tx.Rollback()
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Inventory item not found"})
-			}
-			inventoryItem.StockLevel -= recipeItem.QuantityUsed * float64(item.Quantity)
-			if err := tx.Save(&inventoryItem).Error; err != nil {
-				tx.Rollback()
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update inventory"})
-			}
+		changes, err = stock.ModifierChanges(optionIDs, item.Quantity, -1, movement)
+		if err != nil {
+			return checkoutResult{}, err
 		}
+		stockChanges = append(stockChanges, changes...)
+	}
+
+	// Cek kekurangan stok sesuai policy sebelum stok dipotong
+	shortages, err := stock.CheckShortages(stockChanges, services.DefaultStockPolicy(tx))
+	if err != nil {
+		return checkoutResult{}, err
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to commit transaction"})
+	// Potong stok semua bahan sekaligus secara atomik
+	stockItems, err := stock.Apply(stockChanges)
+	if err != nil {
+		return checkoutResult{}, err
 	}
 
-	return c.JSON(fiber.Map{"message": "Transaction successful"})
+	return checkoutResult{Transaction: transaction, Shortages: shortages, StockItems: stockItems}, nil
+}
+
+// recipeUnitCost menghitung biaya bahan baku untuk satu unit produk
+func recipeUnitCost(db *gorm.DB, productID uint) (float64, error) {
+	var cost float64
+	err := db.Table("recipe_items ri").
+		Select("coalesce(sum(ri.quantity_used * ii.cost_per_unit), 0)").
+		Joins("join inventory_items ii on ii.id = ri.inventory_item_id").
+		Where("ri.product_id = ?", productID).
+		Row().Scan(&cost)
+	return cost, err
 }
 
 type FinancialReportResponse struct {
-	TotalRevenue   float64 `json:"total_revenue"`
-	PaymentMethods []struct {
+	GrossSales       float64 `json:"gross_sales"`
+	Discounts        float64 `json:"discounts"`
+	ServiceCharges   float64 `json:"service_charges"`
+	Taxes            float64 `json:"taxes"`          // dipungut saat penjualan
+	Refunds          float64 `json:"refunds"`        // termasuk pajak yang dikembalikan
+	RefundedTaxes    float64 `json:"refunded_taxes"` // bagian pajak dari refund di periode ini
+	NetSales         float64 `json:"net_sales"`
+	OperationalCosts float64 `json:"operational_costs"`
+	Purchases        float64 `json:"purchases"`
+	PaymentMethods   []struct {
 		PaymentMethod string  `json:"payment_method"`
 		TotalAmount   float64 `json:"total_amount"`
 	} `json:"payment_methods"`
 }
 
 func GetFinancialReport(c *fiber.Ctx) error {
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
-
-	var startDate, endDate time.Time
-	var err error
-
-	if startDateStr != "" {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid start_date format. Use YYYY-MM-DD"})
-		}
+	startDate, endDate, err := parseDateRange(c)
+	if err != nil {
+		return respondError(c, err, "Invalid date range")
 	}
 
-	if endDateStr != "" {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid end_date format. Use YYYY-MM-DD"})
-		}
-		endDate = endDate.Add(24*time.Hour - time.Nanosecond)
-	}
+	// Transaksi yang di-void tidak dihitung sebagai penjualan.
+	// Penjualan kotor dihitung sebelum diskon; pajak dihitung penuh saat penjualan.
+	var grossSales, discounts, serviceCharges, taxes, collected float64
+	query := database.DB.Model(&models.Transaction{}).Where("status <> ?", models.TransactionStatusVoided)
+	query = whereDateRange(query, "transaction_time", startDate, endDate)
+	query.Select(`coalesce(sum(subtotal), 0), coalesce(sum(discount_amount), 0),
+		coalesce(sum(service_charge_amount), 0), coalesce(sum(tax_amount), 0),
+		coalesce(sum(total_amount), 0)`).
+		Row().Scan(&grossSales, &discounts, &serviceCharges, &taxes, &collected)
 
-	var totalRevenue float64
-	query := database.DB.Model(&models.Transaction{})
-	if !startDate.IsZero() {
-		query = query.Where("transaction_time >= ?", startDate)
-	}
-	if !endDate.IsZero() {
-		query = query.Where("transaction_time <= ?", endDate)
-	}
-	query.Select("sum(total_amount)").Row().Scan(&totalRevenue)
+	// Refund dan pajak yang ikut dikembalikan dihitung berdasarkan waktu refund dilakukan,
+	// sehingga angka periode yang sudah lewat tidak berubah karena refund di kemudian hari
+	var refunds, refundedTaxes float64
+	query = database.DB.Table("refunds r").Joins("join transactions t on t.id = r.transaction_id")
+	query = whereDateRange(query, "r.created_at", startDate, endDate)
+	query.Select("coalesce(sum(r.amount), 0), coalesce(sum(t.tax_amount * "+refundShareSQL+"), 0)").
+		Row().Scan(&refunds, &refundedTaxes)
+
+	// Pengeluaran: biaya operasional dan pembelian bahan baku yang sudah diterima
+	var operationalCosts, purchases float64
+	query = whereDateRange(database.DB.Model(&models.OperationalCost{}), "date", startDate, endDate)
+	query.Select("coalesce(sum(amount), 0)").Row().Scan(&operationalCosts)
+	query = whereDateRange(database.DB.Model(&models.PurchaseReceipt{}), "received_at", startDate, endDate)
+	query.Select("coalesce(sum(amount), 0)").Row().Scan(&purchases)
 
 	var paymentMethods []struct {
 		PaymentMethod string
 		TotalAmount   float64
 	}
-	query = database.DB.Model(&models.Transaction{}).Select("payment_method, sum(total_amount) as total_amount").Group("payment_method")
-	if !startDate.IsZero() {
-		query = query.Where("transaction_time >= ?", startDate)
-	}
-	if !endDate.IsZero() {
-		query = query.Where("transaction_time <= ?", endDate)
-	}
+	// Per metode dihitung dari baris pembayaran, sehingga transaksi split terbagi dengan benar
+	query = database.DB.Table("transaction_payments tp").
+		Select("tp.payment_method, sum(tp.amount) as total_amount").
+		Joins("join transactions t on t.id = tp.transaction_id").
+		Where("t.status <> ?", models.TransactionStatusVoided).
+		Group("tp.payment_method").
+		Order("total_amount desc")
+	query = whereDateRange(query, "t.transaction_time", startDate, endDate)
 	query.Scan(&paymentMethods)
 
 	response := FinancialReportResponse{
-		TotalRevenue: totalRevenue,
+		GrossSales:       grossSales,
+		Discounts:        discounts,
+		ServiceCharges:   serviceCharges,
+		Taxes:            taxes,
+		Refunds:          services.RoundMoney(refunds),
+		RefundedTaxes:    services.RoundMoney(refundedTaxes),
+		NetSales:         services.RoundMoney(collected - taxes - (refunds - refundedTaxes)), // pajak adalah titipan, bukan penjualan
+		OperationalCosts: operationalCosts,
+		Purchases:        purchases,
 	}
 	for _, pm := range paymentMethods {
 		response.PaymentMethods = append(response.PaymentMethods, struct {
@@ -181,3 +480,38 @@ func GetFinancialReport(c *fiber.Ctx) error {
 
 	return c.JSON(response)
 }
+
+// respondError turns an error returned from inside db.Transaction into a JSON
+// response, using the status code of a *fiber.Error when there is one.
+// Kekurangan stok dikembalikan lengkap supaya kasir tahu bahan apa yang habis.
+func respondError(c *fiber.Ctx, err error, fallback string) error {
+	status, body := errorResponse(err, fallback)
+	return c.Status(status).JSON(body)
+}
+
+// errorResponse maps an error to its HTTP status and response body
+func errorResponse(err error, fallback string) (int, fiber.Map) {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code, fiber.Map{"error": fe.Message}
+	}
+	var stockErr *services.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return fiber.StatusConflict, fiber.Map{
+			"error":     "Insufficient stock",
+			"shortages": stockErr.Shortages,
+		}
+	}
+	if errors.Is(err, services.ErrInsufficientPoints) {
+		return fiber.StatusConflict, fiber.Map{"error": "Insufficient loyalty points"}
+	}
+	var voucherErr *services.VoucherError
+	if errors.As(err, &voucherErr) {
+		return fiber.StatusBadRequest, fiber.Map{"error": voucherErr.Reason}
+	}
+	if errors.Is(err, services.ErrInventoryItemNotFound) {
+		return fiber.StatusNotFound, fiber.Map{"error": "Inventory item not found"}
+	}
+	log.Printf("%s: %v", fallback, err)
+	return fiber.StatusInternalServerError, fiber.Map{"error": fallback}
+}
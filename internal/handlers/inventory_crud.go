@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"hayoon-bite-backend/internal/middleware"
 	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,6 +16,14 @@ type InventoryRequest struct {
 	Name       string  `json:"name" validate:"required"`
 	StockLevel float64 `json:"stock_level" validate:"gte=0"`
 	Unit       string  `json:"unit" validate:"required"`
+	// Opsional; normalnya dihitung otomatis dari harga beli saat stock-in
+	CostPerUnit *float64 `json:"cost_per_unit" validate:"omitempty,gte=0"`
+
+	// Kosong = ikut pengaturan global
+	StockPolicy models.StockPolicy `json:"stock_policy"`
+
+	// Opsional; notifikasi stok menipis saat stok <= nilai ini (0 = mati)
+	LowStockThreshold *float64 `json:"low_stock_threshold" validate:"omitempty,gte=0"`
 }
 
 // CreateInventoryItem handles creating a new inventory item
@@ -23,6 +34,16 @@ func CreateInventoryItem(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 		}
 
+		if req.CostPerUnit != nil && *req.CostPerUnit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cost_per_unit cannot be negative"})
+		}
+		if req.StockPolicy != "" && !req.StockPolicy.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock_policy, use block, warn or allow"})
+		}
+		if req.LowStockThreshold != nil && *req.LowStockThreshold < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "low_stock_threshold cannot be negative"})
+		}
+
 		// Cek apakah item dengan nama yang sama sudah ada
 		var existing models.InventoryItem
 		if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
@@ -30,15 +51,34 @@ func CreateInventoryItem(db *gorm.DB) fiber.Handler {
 		}
 
 		newItem := models.InventoryItem{
-			Name:       req.Name,
-			StockLevel: req.StockLevel,
-			Unit:       req.Unit,
+			Name:        req.Name,
+			StockLevel:  req.StockLevel,
+			Unit:        req.Unit,
+			StockPolicy: req.StockPolicy,
+		}
+		if req.CostPerUnit != nil {
+			newItem.CostPerUnit = *req.CostPerUnit
+		}
+		if req.LowStockThreshold != nil {
+			newItem.LowStockThreshold = *req.LowStockThreshold
 		}
 
-		if err := db.Create(&newItem).Error; err != nil {
+		userID, _, _ := middleware.GetUserFromContext(c)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&newItem).Error; err != nil {
+				return err
+			}
+			return services.NewStockService(tx).RecordOpeningBalance(newItem, services.MovementInfo{
+				Type:   models.StockMovementAdjustment,
+				UserID: &userID,
+				Note:   "Opening balance",
+			})
+		})
+		if err != nil {
 			log.Printf("Error creating inventory item: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create inventory item"})
 		}
+		services.Events.PublishStockLevels([]models.InventoryItem{newItem})
 
 		return c.Status(fiber.StatusCreated).JSON(newItem)
 	}
@@ -57,27 +97,60 @@ func UpdateInventoryItem(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 		}
 
+		if req.CostPerUnit != nil && *req.CostPerUnit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cost_per_unit cannot be negative"})
+		}
+		if req.StockPolicy != "" && !req.StockPolicy.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock_policy, use block, warn or allow"})
+		}
+		if req.LowStockThreshold != nil && *req.LowStockThreshold < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "low_stock_threshold cannot be negative"})
+		}
+
 		// Cek duplikasi nama, kecuali untuk item itu sendiri
 		var existing models.InventoryItem
 		if err := db.Where("name = ? AND id != ?", req.Name, id).First(&existing).Error; err == nil {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another inventory item with this name already exists"})
 		}
 
-		updateData := models.InventoryItem{
-			Name:       req.Name,
-			StockLevel: req.StockLevel,
-			Unit:       req.Unit,
+		userID, _, _ := middleware.GetUserFromContext(c)
+		var item models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Stok diubah lewat StockService supaya tidak menimpa penjualan yang sedang berjalan
+			movement := services.MovementInfo{
+				Type:   models.StockMovementAdjustment,
+				UserID: &userID,
+				Note:   "Manual edit",
+			}
+			if _, _, err := services.NewStockService(tx).SetLevel(uint(id), req.StockLevel, movement); err != nil {
+				return err
+			}
+
+			updates := map[string]interface{}{
+				"name":         req.Name,
+				"unit":         req.Unit,
+				"stock_policy": req.StockPolicy,
+			}
+			if req.CostPerUnit != nil {
+				updates["cost_per_unit"] = *req.CostPerUnit
+			}
+			if req.LowStockThreshold != nil {
+				updates["low_stock_threshold"] = *req.LowStockThreshold
+			}
+			if err := tx.Model(&models.InventoryItem{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+				return err
+			}
+			return tx.First(&item, id).Error
+		})
+
+		if errors.Is(err, services.ErrInventoryItemNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Inventory item not found"})
 		}
-
-		result := db.Model(&models.InventoryItem{}).Where("id = ?", id).Updates(updateData)
-		if result.Error != nil {
-			log.Printf("Error updating inventory item: %v", result.Error)
+		if err != nil {
+			log.Printf("Error updating inventory item: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update inventory item"})
 		}
-
-		if result.RowsAffected == 0 {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Inventory item not found"})
-		}
+		services.Events.PublishStockLevels([]models.InventoryItem{item})
 
 		return c.JSON(fiber.Map{"message": "Inventory item updated successfully"})
 	}
@@ -111,3 +184,41 @@ func DeleteInventoryItem(db *gorm.DB) fiber.Handler {
 		return c.JSON(fiber.Map{"message": "Inventory item deleted successfully"})
 	}
 }
+
+// GetInventoryMovements handles fetching the stock movement ledger of an item,
+// filtered by start_date/end_date (YYYY-MM-DD) and optionally by type
+func GetInventoryMovements(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid item ID"})
+		}
+
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		var item models.InventoryItem
+		if err := db.First(&item, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Inventory item not found"})
+		}
+
+		query := db.Where("inventory_item_id = ?", id)
+		if movementType := c.Query("type"); movementType != "" {
+			query = query.Where("type = ?", movementType)
+		}
+		query = whereDateRange(query, "created_at", startDate, endDate)
+
+		var movements []models.StockMovement
+		if err := query.Order("created_at desc, id desc").Find(&movements).Error; err != nil {
+			log.Printf("Error fetching stock movements: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch stock movements"})
+		}
+
+		return c.JSON(fiber.Map{
+			"item":      item,
+			"movements": movements,
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderStatusRequest defines the body for moving an order to the next status
+type OrderStatusRequest struct {
+	Status models.OrderStatus `json:"status" validate:"required"`
+}
+
+// KitchenOrder is an order as shown on the kitchen display (tanpa harga)
+type KitchenOrder struct {
+	ID              uint               `json:"id"`
+	QueueNumber     int                `json:"queue_number"`
+	OrderStatus     models.OrderStatus `json:"order_status"`
+	TransactionTime time.Time          `json:"transaction_time"`
+	PreparingAt     *time.Time         `json:"preparing_at,omitempty"`
+	ReadyAt         *time.Time         `json:"ready_at,omitempty"`
+	PickedUpAt      *time.Time         `json:"picked_up_at,omitempty"`
+	Voided          bool               `json:"voided,omitempty"` // dibatalkan kasir, hapus dari layar
+	Items           []KitchenOrderItem `json:"items"`
+}
+
+// KitchenOrderItem is one line to prepare, with the names of the chosen modifiers
+type KitchenOrderItem struct {
+	ProductName string   `json:"product_name"`
+	Quantity    int      `json:"quantity"`
+	Modifiers   []string `json:"modifiers,omitempty"`
+}
+
+// activeOrderStatuses adalah pesanan yang masih tampil di layar dapur
+var activeOrderStatuses = []models.OrderStatus{models.OrderStatusQueued, models.OrderStatusPreparing, models.OrderStatusReady}
+
+func toKitchenOrder(transaction models.Transaction) KitchenOrder {
+	order := KitchenOrder{
+		ID:              transaction.ID,
+		QueueNumber:     transaction.QueueNumber,
+		OrderStatus:     transaction.OrderStatus,
+		TransactionTime: transaction.TransactionTime,
+		PreparingAt:     transaction.PreparingAt,
+		ReadyAt:         transaction.ReadyAt,
+		PickedUpAt:      transaction.PickedUpAt,
+		Voided:          transaction.Status == models.TransactionStatusVoided,
+		Items:           make([]KitchenOrderItem, 0, len(transaction.Items)),
+	}
+	for _, item := range transaction.Items {
+		// Item yang sudah di-refund tidak perlu dibuat
+		quantity := item.Quantity - item.RefundedQuantity
+		if quantity <= 0 {
+			continue
+		}
+		line := KitchenOrderItem{ProductName: item.ProductName, Quantity: quantity}
+		for _, modifier := range item.Modifiers {
+			line.Modifiers = append(line.Modifiers, modifier.Name)
+		}
+		order.Items = append(order.Items, line)
+	}
+	return order
+}
+
+// assignQueueNumber gives a new order the next queue number of the day
+func assignQueueNumber(tx *gorm.DB, transaction *models.Transaction) error {
+	number, err := services.NextQueueNumber(tx, time.Now())
+	if err != nil {
+		return err
+	}
+	transaction.QueueNumber = number
+	return tx.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("queue_number", number).Error
+}
+
+// GetKitchenOrders handles listing the orders that still have to be prepared or
+// picked up, oldest first. Gunakan ?status=queued,preparing untuk menyaring.
+func GetKitchenOrders(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		statuses := activeOrderStatuses
+		if param := c.Query("status"); param != "" {
+			statuses = nil
+			for _, s := range strings.Split(param, ",") {
+				status := models.OrderStatus(strings.TrimSpace(s))
+				if !status.Valid() {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status: " + s})
+				}
+				statuses = append(statuses, status)
+			}
+		}
+
+		var transactions []models.Transaction
+		err := db.
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Where("order_status IN ? AND status <> ?", statuses, models.TransactionStatusVoided).
+			Order("transaction_time, id").
+			Find(&transactions).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch orders")
+		}
+
+		orders := make([]KitchenOrder, 0, len(transactions))
+		for _, transaction := range transactions {
+			orders = append(orders, toKitchenOrder(transaction))
+		}
+		return c.JSON(orders)
+	}
+}
+
+// UpdateOrderStatus handles moving an order forward, e.g. from queued to preparing.
+// Status hanya boleh maju (boleh melompati langkah, mis. queued langsung ready).
+func UpdateOrderStatus(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+		}
+
+		var req OrderStatusRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if !req.Status.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status, use queued, preparing, ready or picked_up"})
+		}
+
+		var transaction models.Transaction
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Order not found")
+			}
+			if transaction.Status == models.TransactionStatusVoided {
+				return fiber.NewError(fiber.StatusConflict, "Order has been voided")
+			}
+			if !transaction.OrderStatus.CanMoveTo(req.Status) {
+				return fiber.NewError(fiber.StatusConflict, "Cannot change order status from "+string(transaction.OrderStatus)+" to "+string(req.Status))
+			}
+
+			now := time.Now()
+			updates := map[string]interface{}{"order_status": req.Status}
+			switch req.Status {
+			case models.OrderStatusPreparing:
+				updates["preparing_at"] = now
+			case models.OrderStatusReady:
+				updates["ready_at"] = now
+			case models.OrderStatusPickedUp:
+				updates["picked_up_at"] = now
+			}
+			if err := tx.Model(&transaction).Updates(updates).Error; err != nil {
+				return err
+			}
+
+			return tx.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+				Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+				First(&transaction, id).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to update order status")
+		}
+
+		order := toKitchenOrder(transaction)
+		services.Events.Publish(services.EventOrderStatusChanged, order)
+		return c.JSON(order)
+	}
+}
+
+// GetOrderQueue handles the customer-facing queue display: the queue numbers of
+// today's orders that are being prepared and those that are ready to pick up.
+// Publik, hanya berisi nomor antrean.
+func GetOrderQueue(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		var orders []models.Transaction
+		err := db.Select("queue_number", "order_status").
+			Where("order_status IN ? AND status <> ? AND queue_number > 0 AND transaction_time >= ?",
+				activeOrderStatuses, models.TransactionStatusVoided, startOfDay).
+			Order("queue_number").
+			Find(&orders).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch queue")
+		}
+
+		preparing, ready := []int{}, []int{}
+		for _, order := range orders {
+			if order.OrderStatus == models.OrderStatusReady {
+				ready = append(ready, order.QueueNumber)
+			} else {
+				preparing = append(preparing, order.QueueNumber)
+			}
+		}
+		return c.JSON(fiber.Map{
+			"preparing": preparing,
+			"ready":     ready,
+		})
+	}
+}
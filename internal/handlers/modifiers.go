@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"fmt"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ModifierGroupRequest defines one modifier group with its options and their recipes
+type ModifierGroupRequest struct {
+	Name         string `json:"name"`
+	MinSelect    int    `json:"min_select"`
+	MaxSelect    int    `json:"max_select"` // 0 = tanpa batas
+	DisplayOrder int    `json:"display_order"`
+	Options      []struct {
+		Name         string  `json:"name"`
+		PriceDelta   float64 `json:"price_delta"`
+		DisplayOrder int     `json:"display_order"`
+		Recipe       []struct {
+			InventoryItemID uint    `json:"inventory_item_id"`
+			QuantityUsed    float64 `json:"quantity_used"`
+		} `json:"recipe"`
+	} `json:"options"`
+}
+
+// loadModifierGroups returns the modifier groups of a product with their options and recipes
+func loadModifierGroups(db *gorm.DB, productID uint) ([]models.ModifierGroup, error) {
+	groups := []models.ModifierGroup{}
+	err := db.Where("product_id = ?", productID).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("display_order, id") }).
+		Preload("Options.Recipe").
+		Order("display_order, id").
+		Find(&groups).Error
+	return groups, err
+}
+
+// GetProductModifiers handles fetching the modifier groups of a product
+func GetProductModifiers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		}
+
+		var count int64
+		db.Model(&models.Product{}).Where("id = ?", id).Count(&count)
+		if count == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		}
+
+		groups, err := loadModifierGroups(db, uint(id))
+		if err != nil {
+			return respondError(c, err, "Failed to fetch modifiers")
+		}
+		return c.JSON(groups)
+	}
+}
+
+// UpdateProductModifiers handles replacing all modifier groups of a product,
+// sama seperti resep yang selalu diganti seluruhnya saat produk di-update
+func UpdateProductModifiers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		}
+
+		var req []ModifierGroupRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var groups []models.ModifierGroup
+		for _, g := range req {
+			if g.Name == "" || len(g.Options) == 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Each modifier group needs a name and at least one option"})
+			}
+			if g.MinSelect < 0 || g.MaxSelect < 0 || (g.MaxSelect > 0 && g.MinSelect > g.MaxSelect) || g.MinSelect > len(g.Options) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid min_select/max_select for modifier group " + g.Name})
+			}
+
+			group := models.ModifierGroup{
+				ProductID:    uint(id),
+				Name:         g.Name,
+				MinSelect:    g.MinSelect,
+				MaxSelect:    g.MaxSelect,
+				DisplayOrder: g.DisplayOrder,
+			}
+			for _, o := range g.Options {
+				if o.Name == "" || o.PriceDelta < 0 {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Modifier option needs a name and price_delta cannot be negative"})
+				}
+				option := models.ModifierOption{
+					Name:         o.Name,
+					PriceDelta:   o.PriceDelta,
+					DisplayOrder: o.DisplayOrder,
+				}
+				for _, r := range o.Recipe {
+					if r.QuantityUsed <= 0 {
+						return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Modifier recipe quantity must be greater than zero"})
+					}
+					option.Recipe = append(option.Recipe, models.ModifierRecipeItem{
+						InventoryItemID: r.InventoryItemID,
+						QuantityUsed:    r.QuantityUsed,
+					})
+				}
+				group.Options = append(group.Options, option)
+			}
+			groups = append(groups, group)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var product models.Product
+			if err := tx.First(&product, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Product not found")
+			}
+
+			// Hapus modifier lama (opsi & resepnya ikut terhapus lewat ON DELETE CASCADE)
+			if err := tx.Where("product_id = ?", product.ID).Delete(&models.ModifierGroup{}).Error; err != nil {
+				return err
+			}
+			for i := range groups {
+				for _, option := range groups[i].Options {
+					for _, r := range option.Recipe {
+						var count int64
+						tx.Model(&models.InventoryItem{}).Where("id = ?", r.InventoryItemID).Count(&count)
+						if count == 0 {
+							return fiber.NewError(fiber.StatusBadRequest, "Inventory item not found")
+						}
+					}
+				}
+				if err := tx.Create(&groups[i]).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to update modifiers")
+		}
+
+		result, err := loadModifierGroups(db, uint(id))
+		if err != nil {
+			return respondError(c, err, "Failed to fetch modifiers")
+		}
+		return c.JSON(result)
+	}
+}
+
+// selectModifiers validates the modifier options chosen for a product against
+// its groups (opsi harus milik produk, tidak dobel, dan sesuai min/max per grup)
+func selectModifiers(db *gorm.DB, productID uint, optionIDs []uint) ([]models.ModifierOption, error) {
+	groups, err := loadModifierGroups(db, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if chosen[id] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Modifier option selected more than once")
+		}
+		chosen[id] = true
+	}
+
+	var selected []models.ModifierOption
+	for _, group := range groups {
+		count := 0
+		for _, option := range group.Options {
+			if chosen[option.ID] {
+				selected = append(selected, option)
+				delete(chosen, option.ID)
+				count++
+			}
+		}
+		if count < group.MinSelect {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Choose at least %d option(s) for %s", group.MinSelect, group.Name))
+		}
+		if group.MaxSelect > 0 && count > group.MaxSelect {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Choose at most %d option(s) for %s", group.MaxSelect, group.Name))
+		}
+	}
+	if len(chosen) > 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Modifier option does not belong to this product")
+	}
+	return selected, nil
+}
+
+// modifierUnitCost menghitung biaya bahan baku tambahan untuk satu opsi modifier
+func modifierUnitCost(db *gorm.DB, optionID uint) (float64, error) {
+	var cost float64
+	err := db.Table("modifier_recipe_items mri").
+		Select("coalesce(sum(mri.quantity_used * ii.cost_per_unit), 0)").
+		Joins("join inventory_items ii on ii.id = mri.inventory_item_id").
+		Where("mri.modifier_option_id = ?", optionID).
+		Row().Scan(&cost)
+	return cost, err
+}
+
+// itemStockChanges returns the stock changes for quantity units of a sold item,
+// termasuk bahan dari modifier yang dipilih saat penjualan
+func itemStockChanges(stock *services.StockService, item models.TransactionItem, quantity int, sign float64, info services.MovementInfo) ([]services.StockChange, error) {
+	changes, err := stock.RecipeChanges(item.ProductID, quantity, sign, info)
+	if err != nil {
+		return nil, err
+	}
+
+	var optionIDs []uint
+	if err := stock.DB.Model(&models.TransactionItemModifier{}).
+		Where("transaction_item_id = ? AND modifier_option_id IS NOT NULL", item.ID).
+		Pluck("modifier_option_id", &optionIDs).Error; err != nil {
+		return nil, err
+	}
+	modifierChanges, err := stock.ModifierChanges(optionIDs, quantity, sign, info)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, modifierChanges...), nil
+}
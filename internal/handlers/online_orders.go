@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxOnlineOrderItems    = 50
+	maxOnlineOrderQuantity = 100
+	// maxPickupAhead membatasi seberapa jauh hari pengambilan boleh dipesan
+	maxPickupAhead = 7 * 24 * time.Hour
+	// onlineOrderKeyPrefix + kode pesanan dipakai sebagai idempotency key transaksinya
+	onlineOrderKeyPrefix = "online-"
+)
+
+// MenuCategory is a category of the public menu with its products
+type MenuCategory struct {
+	ID       *uint         `json:"id"` // null untuk produk tanpa kategori
+	Name     string        `json:"name"`
+	Products []MenuProduct `json:"products"`
+}
+
+// MenuProduct is a product as shown to customers (tanpa resep dan biaya)
+type MenuProduct struct {
+	ID             uint                `json:"id"`
+	Name           string              `json:"name"`
+	Price          float64             `json:"price"`
+	ImagePath      string              `json:"image_path"`
+	ModifierGroups []MenuModifierGroup `json:"modifier_groups"`
+}
+
+type MenuModifierGroup struct {
+	ID        uint                 `json:"id"`
+	Name      string               `json:"name"`
+	MinSelect int                  `json:"min_select"`
+	MaxSelect int                  `json:"max_select"`
+	Options   []MenuModifierOption `json:"options"`
+}
+
+type MenuModifierOption struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+}
+
+// menuProducts returns the query for products customers may order:
+// produk di kategori aktif atau tanpa kategori, urut seperti di POS
+func menuProducts(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Product{}).
+		Preload("Category").
+		Joins("left join product_categories pc on pc.id = products.category_id").
+		Where("pc.id is null or pc.is_active = ?", true).
+		Order("pc.display_order nulls last, pc.name, products.name")
+}
+
+// GetPublicMenu handles the public menu for online ordering, grouped by category
+func GetPublicMenu(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var products []models.Product
+		if err := menuProducts(db).Find(&products).Error; err != nil {
+			return respondError(c, err, "Failed to fetch menu")
+		}
+
+		menu := []MenuCategory{}
+		for _, product := range products {
+			groups, err := loadModifierGroups(db, product.ID)
+			if err != nil {
+				return respondError(c, err, "Failed to fetch menu")
+			}
+			item := MenuProduct{
+				ID:             product.ID,
+				Name:           product.Name,
+				Price:          product.Price,
+				ImagePath:      product.ImagePath,
+				ModifierGroups: make([]MenuModifierGroup, 0, len(groups)),
+			}
+			for _, group := range groups {
+				menuGroup := MenuModifierGroup{
+					ID:        group.ID,
+					Name:      group.Name,
+					MinSelect: group.MinSelect,
+					MaxSelect: group.MaxSelect,
+					Options:   make([]MenuModifierOption, 0, len(group.Options)),
+				}
+				for _, option := range group.Options {
+					menuGroup.Options = append(menuGroup.Options, MenuModifierOption{ID: option.ID, Name: option.Name, PriceDelta: option.PriceDelta})
+				}
+				item.ModifierGroups = append(item.ModifierGroups, menuGroup)
+			}
+
+			// Produk sudah urut per kategori, jadi cukup bandingkan dengan kategori terakhir
+			category := MenuCategory{Name: "Lainnya"}
+			if product.Category != nil {
+				category = MenuCategory{ID: product.CategoryID, Name: product.Category.Name}
+			}
+			if n := len(menu); n == 0 || !sameCategory(menu[n-1].ID, category.ID) {
+				menu = append(menu, category)
+			}
+			menu[len(menu)-1].Products = append(menu[len(menu)-1].Products, item)
+		}
+		return c.JSON(menu)
+	}
+}
+
+func sameCategory(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// OnlineOrderRequest defines the body of a pickup order placed by a customer
+type OnlineOrderRequest struct {
+	CustomerName  string                   `json:"customer_name"`
+	CustomerPhone string                   `json:"customer_phone"`
+	Note          string                   `json:"note"`
+	PickupTime    time.Time                `json:"pickup_time"`
+	Items         []TransactionItemRequest `json:"items"`
+}
+
+// validate checks the customer details and the pickup time
+func (r *OnlineOrderRequest) validate(now time.Time) error {
+	r.CustomerName = strings.TrimSpace(r.CustomerName)
+	r.CustomerPhone = strings.TrimSpace(r.CustomerPhone)
+	r.Note = strings.TrimSpace(r.Note)
+
+	if r.CustomerName == "" || len(r.CustomerName) > 100 {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters")
+	}
+	digits := 0
+	for _, ch := range r.CustomerPhone {
+		switch {
+		case ch >= '0' && ch <= '9':
+			digits++
+		case ch == '+' || ch == ' ' || ch == '-':
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid phone number")
+		}
+	}
+	if digits < 8 || digits > 15 {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid phone number")
+	}
+	if len(r.Note) > 500 {
+		return fiber.NewError(fiber.StatusBadRequest, "Note must be at most 500 characters")
+	}
+
+	if r.PickupTime.IsZero() {
+		return fiber.NewError(fiber.StatusBadRequest, "pickup_time is required")
+	}
+	if r.PickupTime.Before(now) || r.PickupTime.After(now.Add(maxPickupAhead)) {
+		return fiber.NewError(fiber.StatusBadRequest, "pickup_time must be within the next 7 days")
+	}
+
+	if len(r.Items) == 0 || len(r.Items) > maxOnlineOrderItems {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("An order must contain between 1 and %d items", maxOnlineOrderItems))
+	}
+	for _, item := range r.Items {
+		if item.Quantity <= 0 || item.Quantity > maxOnlineOrderQuantity {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Quantity must be between 1 and %d", maxOnlineOrderQuantity))
+		}
+	}
+	return nil
+}
+
+// priceOnlineOrder builds the order lines and estimates the total with the
+// automatic promotions and tax rules in effect now. Total akhir tetap dihitung
+// ulang oleh checkout saat pesanan dikonfirmasi.
+func priceOnlineOrder(db *gorm.DB, items []TransactionItemRequest, now time.Time) ([]models.OnlineOrderItem, float64, error) {
+	lines := make([]models.OnlineOrderItem, 0, len(items))
+	cart := make([]services.CartLine, 0, len(items))
+	var subtotal float64
+	for _, item := range items {
+		var product models.Product
+		if err := menuProducts(db).First(&product, "products.id = ?", item.ProductID).Error; err != nil {
+			return nil, 0, fiber.NewError(fiber.StatusNotFound, "Product not found")
+		}
+		options, err := selectModifiers(db, product.ID, item.ModifierOptionIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		line := models.OnlineOrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   product.Price,
+		}
+		for _, option := range options {
+			line.UnitPrice += option.PriceDelta
+			line.Modifiers = append(line.Modifiers, models.OnlineOrderItemModifier{
+				ModifierOptionID: &option.ID,
+				Name:             option.Name,
+				PriceDelta:       option.PriceDelta,
+			})
+		}
+		lines = append(lines, line)
+		cart = append(cart, services.CartLine{
+			ProductID:  product.ID,
+			CategoryID: product.CategoryID,
+			Quantity:   item.Quantity,
+			UnitPrice:  line.UnitPrice,
+		})
+		subtotal += line.UnitPrice * float64(item.Quantity)
+	}
+
+	// Tanpa voucher, jadi Apply tidak mengubah apa pun di database
+	discounts, err := services.NewPromotionService(db).Apply(cart, "", now)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, discount := range discounts {
+		subtotal -= discount.Amount
+	}
+	rules, err := services.ActiveTaxRules(db)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lines, services.CalculateCharges(rules, subtotal).Total, nil
+}
+
+// generateOnlineOrderCode creates a random order code like HB-7K2M9Q that is not in use yet
+func generateOnlineOrderCode(db *gorm.DB) (string, error) {
+	for {
+		suffix, err := randomCode(6)
+		if err != nil {
+			return "", err
+		}
+		code := "HB-" + suffix
+
+		var count int64
+		db.Model(&models.OnlineOrder{}).Where("code = ?", code).Count(&count)
+		if count == 0 {
+			return code, nil
+		}
+	}
+}
+
+// PublicOnlineOrder is what a customer sees when looking up an order by its code
+type PublicOnlineOrder struct {
+	Code           string                   `json:"code"`
+	Status         models.OnlineOrderStatus `json:"status"`
+	CustomerName   string                   `json:"customer_name"`
+	PickupTime     time.Time                `json:"pickup_time"`
+	EstimatedTotal float64                  `json:"estimated_total"`
+	Items          []models.OnlineOrderItem `json:"items"`
+	RejectReason   string                   `json:"reject_reason,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+
+	// Terisi setelah pesanan dikonfirmasi dan masuk antrean dapur
+	TotalAmount *float64           `json:"total_amount,omitempty"`
+	QueueNumber int                `json:"queue_number,omitempty"`
+	OrderStatus models.OrderStatus `json:"order_status,omitempty"`
+	Voided      bool               `json:"voided,omitempty"`
+}
+
+func toPublicOnlineOrder(order models.OnlineOrder) PublicOnlineOrder {
+	public := PublicOnlineOrder{
+		Code:           order.Code,
+		Status:         order.Status,
+		CustomerName:   order.CustomerName,
+		PickupTime:     order.PickupTime,
+		EstimatedTotal: order.EstimatedTotal,
+		Items:          order.Items,
+		RejectReason:   order.RejectReason,
+		CreatedAt:      order.CreatedAt,
+	}
+	if t := order.Transaction; t != nil {
+		public.TotalAmount = &t.TotalAmount
+		public.QueueNumber = t.QueueNumber
+		public.OrderStatus = t.OrderStatus
+		public.Voided = t.Status == models.TransactionStatusVoided
+	}
+	return public
+}
+
+// loadOnlineOrder loads an order with its items and the transaction it became, if any
+func loadOnlineOrder(db *gorm.DB, query string, args ...interface{}) (models.OnlineOrder, error) {
+	var order models.OnlineOrder
+	err := db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Transaction").
+		Where(query, args...).
+		First(&order).Error
+	return order, err
+}
+
+// CreateOnlineOrder handles a customer placing a pickup order from the website.
+// Pesanan berstatus pending dan stok belum dipotong sampai dikonfirmasi staf.
+func CreateOnlineOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req OnlineOrderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		now := time.Now()
+		if err := req.validate(now); err != nil {
+			return respondError(c, err, "Invalid order")
+		}
+
+		items, estimate, err := priceOnlineOrder(db, req.Items, now)
+		if err != nil {
+			return respondError(c, err, "Failed to price order")
+		}
+		code, err := generateOnlineOrderCode(db)
+		if err != nil {
+			return respondError(c, err, "Failed to create order")
+		}
+
+		order := models.OnlineOrder{
+			Code:           code,
+			CustomerName:   req.CustomerName,
+			CustomerPhone:  req.CustomerPhone,
+			Note:           req.Note,
+			PickupTime:     req.PickupTime,
+			Status:         models.OnlineOrderPending,
+			EstimatedTotal: estimate,
+			Items:          items,
+		}
+		if err := db.Create(&order).Error; err != nil {
+			return respondError(c, err, "Failed to create order")
+		}
+
+		services.Events.Publish(services.EventOnlineOrderCreated, order)
+		return c.Status(fiber.StatusCreated).JSON(toPublicOnlineOrder(order))
+	}
+}
+
+// GetPublicOnlineOrder handles a customer checking the status of an order by its code
+func GetPublicOnlineOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		code := strings.ToUpper(strings.TrimSpace(c.Params("code")))
+		order, err := loadOnlineOrder(db, "code = ?", code)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		}
+		return c.JSON(toPublicOnlineOrder(order))
+	}
+}
+
+// GetOnlineOrders handles listing online orders for staff, earliest pickup first.
+// Default hanya pesanan pending; gunakan ?status=confirmed atau ?status=all.
+func GetOnlineOrders(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Order("pickup_time, id")
+
+		switch status := models.OnlineOrderStatus(c.Query("status", string(models.OnlineOrderPending))); status {
+		case "all":
+		case models.OnlineOrderPending, models.OnlineOrderConfirmed, models.OnlineOrderRejected:
+			query = query.Where("status = ?", status)
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status, use pending, confirmed, rejected or all"})
+		}
+
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+		query = whereDateRange(query, "pickup_time", startDate, endDate)
+
+		var orders []models.OnlineOrder
+		if err := query.Find(&orders).Error; err != nil {
+			return respondError(c, err, "Failed to fetch online orders")
+		}
+		return c.JSON(orders)
+	}
+}
+
+// GetOnlineOrder handles fetching a single online order for staff
+func GetOnlineOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+		}
+		order, err := loadOnlineOrder(db, "id = ?", id)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Order not found"})
+		}
+		return c.JSON(order)
+	}
+}
+
+// ConfirmOnlineOrderRequest defines how the order is paid, like in a POS checkout
+type ConfirmOnlineOrderRequest struct {
+	PaymentMethod string           `json:"payment_method"`
+	Payments      []PaymentRequest `json:"payments"`
+}
+
+// lockPendingOnlineOrder locks an online order that is still waiting for review
+func lockPendingOnlineOrder(tx *gorm.DB, id int) (models.OnlineOrder, error) {
+	var order models.OnlineOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return order, fiber.NewError(fiber.StatusNotFound, "Order not found")
+	}
+	if order.Status != models.OnlineOrderPending {
+		return order, fiber.NewError(fiber.StatusConflict, "Order has already been "+string(order.Status))
+	}
+	return order, nil
+}
+
+// ConfirmOnlineOrder handles staff accepting an online order.
+//
+// Pesanan dicatat sebagai transaksi di shift kasir yang sedang buka lewat checkout
+// yang sama dengan POS: harga, promo dan pajak dihitung ulang saat ini, stok bahan
+// dipotong, dan pesanan mendapat nomor antrean dapur. Pembayaran dicatat di sini
+// (mis. QRIS yang sudah dibayar, atau tunai yang akan diterima saat diambil).
+func ConfirmOnlineOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+		}
+		var req ConfirmOnlineOrderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var recorded checkoutResult
+		err = db.Transaction(func(tx *gorm.DB) error {
+			order, err := lockPendingOnlineOrder(tx, id)
+			if err != nil {
+				return err
+			}
+			var items []models.OnlineOrderItem
+			if err := tx.Preload("Modifiers").Where("online_order_id = ?", order.ID).Order("id").Find(&items).Error; err != nil {
+				return err
+			}
+
+			checkoutReq := TransactionRequest{PaymentMethod: req.PaymentMethod, Payments: req.Payments}
+			// Poin dikumpulkan jika nomor pemesan sudah terdaftar sebagai pelanggan
+			var customer models.Customer
+			if err := tx.Where("phone = ?", services.NormalizePhone(order.CustomerPhone)).First(&customer).Error; err == nil {
+				checkoutReq.CustomerID = &customer.ID
+			}
+			for _, item := range items {
+				line := TransactionItemRequest{ProductID: item.ProductID, Quantity: item.Quantity}
+				for _, modifier := range item.Modifiers {
+					if modifier.ModifierOptionID == nil {
+						return fiber.NewError(fiber.StatusConflict, "Option "+modifier.Name+" is no longer available, reject the order instead")
+					}
+					line.ModifierOptionIDs = append(line.ModifierOptionIDs, *modifier.ModifierOptionID)
+				}
+				checkoutReq.Items = append(checkoutReq.Items, line)
+			}
+
+			shift, err := findOpenShift(tx, userID)
+			if err != nil {
+				return err
+			}
+			recorded, err = checkout(tx, checkoutReq, userID, shift, time.Now(), onlineOrderKeyPrefix+order.Code)
+			if err != nil {
+				return err
+			}
+			if err := assignQueueNumber(tx, &recorded.Transaction); err != nil {
+				return err
+			}
+
+			return tx.Model(&order).Updates(map[string]interface{}{
+				"status":         models.OnlineOrderConfirmed,
+				"transaction_id": recorded.Transaction.ID,
+				"reviewed_by_id": userID,
+				"reviewed_at":    time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to confirm order")
+		}
+
+		saved, err := loadTransaction(db, recorded.Transaction.ID)
+		if err != nil {
+			return respondError(c, err, "Failed to load transaction")
+		}
+		publishSaleEvents(saved, recorded.StockItems)
+
+		order, err := loadOnlineOrder(db, "id = ?", id)
+		if err != nil {
+			return respondError(c, err, "Failed to load order")
+		}
+		services.Events.Publish(services.EventOnlineOrderUpdated, order)
+
+		response := fiber.Map{
+			"message":      "Order confirmed",
+			"online_order": order,
+			"transaction":  saved,
+		}
+		if len(recorded.Shortages) > 0 {
+			response["warnings"] = recorded.Shortages
+		}
+		return c.JSON(response)
+	}
+}
+
+// RejectOnlineOrderRequest defines the reason shown to the customer
+type RejectOnlineOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RejectOnlineOrder handles staff declining an online order, e.g. when an item is sold out
+func RejectOnlineOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid order ID"})
+		}
+		var req RejectOnlineOrderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" || len(req.Reason) > 500 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Reason is required and must be at most 500 characters"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			order, err := lockPendingOnlineOrder(tx, id)
+			if err != nil {
+				return err
+			}
+			return tx.Model(&order).Updates(map[string]interface{}{
+				"status":         models.OnlineOrderRejected,
+				"reject_reason":  req.Reason,
+				"reviewed_by_id": userID,
+				"reviewed_at":    time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to reject order")
+		}
+
+		order, err := loadOnlineOrder(db, "id = ?", id)
+		if err != nil {
+			return respondError(c, err, "Failed to load order")
+		}
+		services.Events.Publish(services.EventOnlineOrderUpdated, order)
+		return c.JSON(order)
+	}
+}
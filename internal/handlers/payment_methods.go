@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PaymentMethodRequest defines the structure for creating/updating a payment method.
+// Code tidak bisa diubah setelah dibuat karena dipakai di riwayat transaksi.
+type PaymentMethodRequest struct {
+	Code         string `json:"code"`
+	Name         string `json:"name" validate:"required"`
+	IsCash       bool   `json:"is_cash"`
+	IsActive     *bool  `json:"is_active"` // default true
+	DisplayOrder int    `json:"display_order"`
+}
+
+// PaymentRequest is one tender in a checkout.
+// Amount 0 berarti membayar sisa tagihan; Tendered hanya untuk tunai (uang yang diterima).
+type PaymentRequest struct {
+	PaymentMethod string  `json:"payment_method"`
+	Amount        float64 `json:"amount"`
+	Tendered      float64 `json:"tendered"`
+}
+
+// splitPaymentMethod is stored in Transaction.PaymentMethod when there is more than one tender
+const splitPaymentMethod = "split"
+
+var paymentCodePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// normalizePaymentCode makes payment method codes case-insensitive
+func normalizePaymentCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// GetPaymentMethods handles fetching payment methods in display order.
+// Gunakan ?active=true untuk daftar yang ditampilkan di kasir.
+func GetPaymentMethods(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Order("display_order, name")
+		if c.QueryBool("active") {
+			query = query.Where("is_active = ?", true)
+		}
+
+		var methods []models.PaymentMethod
+		if err := query.Find(&methods).Error; err != nil {
+			return respondError(c, err, "Failed to fetch payment methods")
+		}
+		return c.JSON(methods)
+	}
+}
+
+// CreatePaymentMethod handles creating a new payment method
+func CreatePaymentMethod(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req PaymentMethodRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		code := normalizePaymentCode(req.Code)
+		if !paymentCodePattern.MatchString(code) || code == splitPaymentMethod {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Code must use lowercase letters, numbers or underscores"})
+		}
+		if req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name is required"})
+		}
+
+		var count int64
+		db.Model(&models.PaymentMethod{}).Where("code = ?", code).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Payment method with this code already exists"})
+		}
+
+		method := models.PaymentMethod{
+			Code:         code,
+			Name:         req.Name,
+			IsCash:       req.IsCash,
+			IsActive:     req.IsActive == nil || *req.IsActive,
+			DisplayOrder: req.DisplayOrder,
+		}
+		// Select("*") supaya is_active=false tetap tersimpan (bukan diganti default DB)
+		if err := db.Select("*").Omit("id", "created_at", "updated_at").Create(&method).Error; err != nil {
+			return respondError(c, err, "Failed to create payment method")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(method)
+	}
+}
+
+// UpdatePaymentMethod handles updating the name, cash flag, visibility and order of a payment method
+func UpdatePaymentMethod(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payment method ID"})
+		}
+
+		var req PaymentMethodRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name is required"})
+		}
+
+		var method models.PaymentMethod
+		if err := db.First(&method, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Payment method not found"})
+		}
+
+		err = db.Model(&method).Updates(map[string]interface{}{
+			"name":          req.Name,
+			"is_cash":       req.IsCash,
+			"is_active":     req.IsActive == nil || *req.IsActive,
+			"display_order": req.DisplayOrder,
+			"updated_at":    gorm.Expr("now()"),
+		}).Error
+		if err != nil {
+			return respondError(c, err, "Failed to update payment method")
+		}
+
+		db.First(&method, id)
+		return c.JSON(method)
+	}
+}
+
+// DeletePaymentMethod handles deleting a payment method that has never been used
+func DeletePaymentMethod(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payment method ID"})
+		}
+
+		var usedCount int64
+		db.Model(&models.TransactionPayment{}).Where("payment_method_id = ?", id).Count(&usedCount)
+		if usedCount > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Cannot delete a payment method that has been used, deactivate it instead."})
+		}
+
+		result := db.Delete(&models.PaymentMethod{}, id)
+		if result.Error != nil {
+			return respondError(c, result.Error, "Failed to delete payment method")
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Payment method not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Payment method deleted successfully"})
+	}
+}
+
+// buildPayments validates the tenders of a checkout against the amount due and
+// returns the payment lines plus the value for Transaction.PaymentMethod.
+// Tanpa payments, paymentMethod dipakai sebagai satu pembayaran penuh (format lama).
+func buildPayments(db *gorm.DB, paymentMethod string, payments []PaymentRequest, total float64) ([]models.TransactionPayment, string, error) {
+	if len(payments) == 0 {
+		if strings.TrimSpace(paymentMethod) == "" {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, "Payment method is required")
+		}
+		payments = []PaymentRequest{{PaymentMethod: paymentMethod}}
+	}
+
+	var fixed float64
+	restIndex := -1
+	for i, p := range payments {
+		if p.Amount < 0 || p.Tendered < 0 {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, "Payment amounts cannot be negative")
+		}
+		if p.Amount == 0 {
+			if restIndex >= 0 {
+				return nil, "", fiber.NewError(fiber.StatusBadRequest, "Only one payment can pay the remaining amount")
+			}
+			restIndex = i
+		}
+		fixed += p.Amount
+	}
+
+	// Sisa tagihan dibayar oleh baris tanpa amount
+	if restIndex >= 0 {
+		rest := services.RoundMoney(total - fixed)
+		if rest < 0 || (rest == 0 && len(payments) > 1) {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, "Payments exceed the amount due")
+		}
+		payments[restIndex].Amount = rest
+	} else if math.Abs(fixed-total) > 0.005 {
+		return nil, "", fiber.NewError(fiber.StatusBadRequest, "Payments must add up to the amount due")
+	}
+
+	lines := make([]models.TransactionPayment, 0, len(payments))
+	for _, p := range payments {
+		var method models.PaymentMethod
+		err := db.Where("code = ? AND is_active = ?", normalizePaymentCode(p.PaymentMethod), true).First(&method).Error
+		if err != nil {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, "Unknown payment method: "+p.PaymentMethod)
+		}
+
+		line := models.TransactionPayment{
+			PaymentMethodID: method.ID,
+			PaymentMethod:   method.Code,
+			Amount:          p.Amount,
+			Tendered:        p.Amount,
+		}
+		if method.IsCash && p.Tendered > 0 {
+			if p.Tendered < p.Amount {
+				return nil, "", fiber.NewError(fiber.StatusBadRequest, "Tendered cash is less than the amount to pay")
+			}
+			line.Tendered = p.Tendered
+			line.ChangeAmount = services.RoundMoney(p.Tendered - p.Amount)
+		} else if !method.IsCash && p.Tendered > 0 && math.Abs(p.Tendered-p.Amount) > 0.005 {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, "Only cash payments can have change")
+		}
+		lines = append(lines, line)
+	}
+
+	summary := lines[0].PaymentMethod
+	if len(lines) > 1 {
+		summary = splitPaymentMethod
+	}
+	return lines, summary, nil
+}
+
+// cashDrawerCodes returns the codes of the payment methods that go into the cash drawer
+func cashDrawerCodes(db *gorm.DB) (map[string]bool, error) {
+	var codes []string
+	if err := db.Model(&models.PaymentMethod{}).Where("is_cash = ?", true).Pluck("code", &codes).Error; err != nil {
+		return nil, err
+	}
+	cash := map[string]bool{"cash": true}
+	for _, code := range codes {
+		cash[code] = true
+	}
+	return cash, nil
+}
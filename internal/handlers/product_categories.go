@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"hayoon-bite-backend/internal/models"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ProductCategoryRequest defines the structure for creating/updating a product category
+type ProductCategoryRequest struct {
+	Name         string `json:"name" validate:"required"`
+	DisplayOrder int    `json:"display_order"`
+	IsActive     *bool  `json:"is_active"` // default true
+}
+
+// GetProductCategories handles fetching all categories in display order.
+// Gunakan ?active=true untuk hanya menampilkan kategori yang tidak disembunyikan.
+func GetProductCategories(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Order("display_order, name")
+		if c.QueryBool("active") {
+			query = query.Where("is_active = ?", true)
+		}
+
+		var categories []models.ProductCategory
+		if err := query.Find(&categories).Error; err != nil {
+			log.Printf("Error fetching product categories: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch product categories"})
+		}
+		return c.JSON(categories)
+	}
+}
+
+// CreateProductCategory handles creating a new product category
+func CreateProductCategory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ProductCategoryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name is required"})
+		}
+
+		var existing models.ProductCategory
+		if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Category with this name already exists"})
+		}
+
+		category := models.ProductCategory{
+			Name:         req.Name,
+			DisplayOrder: req.DisplayOrder,
+			IsActive:     req.IsActive == nil || *req.IsActive,
+		}
+		// Select("*") supaya is_active=false tetap tersimpan (bukan diganti default DB)
+		if err := db.Select("*").Omit("id", "created_at", "updated_at").Create(&category).Error; err != nil {
+			log.Printf("Error creating product category: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create product category"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(category)
+	}
+}
+
+// UpdateProductCategory handles updating a product category
+func UpdateProductCategory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid category ID"})
+		}
+
+		var req ProductCategoryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var existing models.ProductCategory
+		if err := db.Where("name = ? AND id != ?", req.Name, id).First(&existing).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another category with this name already exists"})
+		}
+
+		var category models.ProductCategory
+		if err := db.First(&category, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Category not found"})
+		}
+
+		category.Name = req.Name
+		category.DisplayOrder = req.DisplayOrder
+		if req.IsActive != nil {
+			category.IsActive = *req.IsActive
+		}
+		if err := db.Save(&category).Error; err != nil {
+			log.Printf("Error updating product category: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update product category"})
+		}
+
+		return c.JSON(category)
+	}
+}
+
+// DeleteProductCategory handles deleting a category; its products become uncategorized
+func DeleteProductCategory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid category ID"})
+		}
+
+		var rowsAffected int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Product{}).Where("category_id = ?", id).Update("category_id", nil).Error; err != nil {
+				return err
+			}
+			result := tx.Delete(&models.ProductCategory{}, id)
+			rowsAffected = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			log.Printf("Error deleting product category: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete product category"})
+		}
+		if rowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Category not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Product category deleted successfully"})
+	}
+}
@@ -15,21 +15,29 @@ import (
 
 // ProductRequest defines the structure for creating/updating a product
 type ProductRequest struct {
-	Name   string `json:"name"`
-	Price  int    `json:"price"`
-	Recipe []struct {
+	Name       string `json:"name"`
+	Price      int    `json:"price"`
+	CategoryID *uint  `json:"category_id"`
+	Recipe     []struct {
 		InventoryItemID int     `json:"inventory_item_id"`
 		QuantityUsed    float64 `json:"quantity_used"`
 	} `json:"recipe"`
 }
 
 // ProductResponse defines the structure for product responses, including the recipe
+// and its cost. RecipeCost dihitung dari cost_per_unit bahan baku saat ini.
 type ProductResponse struct {
-	ID        uint                 `json:"id"`
-	Name      string               `json:"name"`
-	Price     int                  `json:"price"`
-	ImagePath string               `json:"image_path"`
-	Recipe    []RecipeItemResponse `json:"recipe"`
+	ID             uint                   `json:"id"`
+	Name           string                 `json:"name"`
+	Price          int                    `json:"price"`
+	ImagePath      string                 `json:"image_path"`
+	CategoryID     *uint                  `json:"category_id"`
+	CategoryName   string                 `json:"category_name"`
+	Recipe         []RecipeItemResponse   `json:"recipe"`
+	ModifierGroups []models.ModifierGroup `json:"modifier_groups"`
+	RecipeCost     float64                `json:"recipe_cost"`
+	GrossMargin    float64                `json:"gross_margin"`
+	MarginPercent  float64                `json:"margin_percent"`
 }
 
 type RecipeItemResponse struct {
@@ -37,34 +45,63 @@ type RecipeItemResponse struct {
 	InventoryItemName string  `json:"inventory_item_name"`
 	QuantityUsed      float64 `json:"quantity_used"`
 	Unit              string  `json:"unit"`
+	CostPerUnit       float64 `json:"cost_per_unit"`
+	Cost              float64 `json:"cost"`
 }
 
-// GetProducts handles fetching all products with their recipes
+// buildProductResponse loads the recipe of a product and computes its cost and margin
+func buildProductResponse(db *gorm.DB, p models.Product) ProductResponse {
+	var recipeItems []RecipeItemResponse
+	db.Table("recipe_items ri").
+		Select("ri.inventory_item_id, ii.name as inventory_item_name, ri.quantity_used, ii.unit, ii.cost_per_unit, ri.quantity_used * ii.cost_per_unit as cost").
+		Joins("join inventory_items ii on ii.id = ri.inventory_item_id").
+		Where("ri.product_id = ?", p.ID).
+		Scan(&recipeItems)
+
+	response := ProductResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Price:      int(p.Price),
+		ImagePath:  p.ImagePath,
+		CategoryID: p.CategoryID,
+		Recipe:     recipeItems,
+	}
+	if p.Category != nil {
+		response.CategoryName = p.Category.Name
+	}
+	response.ModifierGroups, _ = loadModifierGroups(db, p.ID)
+	for _, item := range recipeItems {
+		response.RecipeCost += item.Cost
+	}
+	response.GrossMargin = p.Price - response.RecipeCost
+	response.MarginPercent = marginPercent(p.Price, response.GrossMargin)
+	return response
+}
+
+// GetProducts handles fetching all products with their recipes.
+// Filter opsional: ?category_id= dan ?active=true (sembunyikan produk di kategori nonaktif).
 func GetProducts(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		query := db.Preload("Category").
+			Joins("left join product_categories pc on pc.id = products.category_id").
+			Order("pc.display_order nulls last, pc.name, products.name")
+		if categoryID := c.QueryInt("category_id"); categoryID > 0 {
+			query = query.Where("products.category_id = ?", categoryID)
+		}
+		if c.QueryBool("active") {
+			query = query.Where("pc.id is null or pc.is_active = ?", true)
+		}
+
 		var products []models.Product
-		// Ambil semua produk, urutkan dari yang terbaru
-		if err := db.Find(&products).Error; err != nil {
+		// Ambil semua produk, urut sesuai urutan kategori
+		if err := query.Find(&products).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch products"})
 		}
 
 		var response []ProductResponse
-		// Untuk setiap produk, ambil resepnya
+		// Untuk setiap produk, ambil resep beserta biaya & marginnya
 		for _, p := range products {
-			var recipeItems []RecipeItemResponse
-			db.Table("recipe_items ri").
-				Select("ri.inventory_item_id, ii.name as inventory_item_name, ri.quantity_used, ii.unit").
-				Joins("join inventory_items ii on ii.id = ri.inventory_item_id").
-				Where("ri.product_id = ?", p.ID).
-				Scan(&recipeItems)
-
-			response = append(response, ProductResponse{
-				ID:        p.ID,
-				Name:      p.Name,
-				Price:     int(p.Price),
-				ImagePath: p.ImagePath,
-				Recipe:    recipeItems,
-			})
+			response = append(response, buildProductResponse(db, p))
 		}
 
 		return c.JSON(response)
@@ -80,26 +117,11 @@ func GetProduct(db *gorm.DB) fiber.Handler {
 		}
 
 		var product models.Product
-		if err := db.First(&product, id).Error; err != nil {
+		if err := db.Preload("Category").First(&product, id).Error; err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
 		}
 
-		var recipeItems []RecipeItemResponse
-		db.Table("recipe_items ri").
-			Select("ri.inventory_item_id, ii.name as inventory_item_name, ri.quantity_used, ii.unit").
-			Joins("join inventory_items ii on ii.id = ri.inventory_item_id").
-			Where("ri.product_id = ?", product.ID).
-			Scan(&recipeItems)
-
-		response := ProductResponse{
-			ID:        product.ID,
-			Name:      product.Name,
-			Price:     int(product.Price),
-			ImagePath: product.ImagePath,
-			Recipe:    recipeItems,
-		}
-
-		return c.JSON(response)
+		return c.JSON(buildProductResponse(db, product))
 	}
 }
 
@@ -123,6 +145,14 @@ func CreateProduct(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product JSON data"})
 		}
 
+		if req.CategoryID != nil {
+			var count int64
+			db.Model(&models.ProductCategory{}).Where("id = ?", *req.CategoryID).Count(&count)
+			if count == 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Product category not found"})
+			}
+		}
+
 		// Handle file upload
 		var imagePath string
 		files := form.File["image"]
@@ -141,9 +171,10 @@ func CreateProduct(db *gorm.DB) fiber.Handler {
 		err = db.Transaction(func(tx *gorm.DB) error {
 			// 1. Buat produk baru
 			newProduct := models.Product{
-				Name:      req.Name,
-				Price:     float64(req.Price),
-				ImagePath: imagePath,
+				Name:       req.Name,
+				Price:      float64(req.Price),
+				CategoryID: req.CategoryID,
+				ImagePath:  imagePath,
 			}
 			if err := tx.Create(&newProduct).Error; err != nil {
 				return err
@@ -197,6 +228,14 @@ func UpdateProduct(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product JSON data"})
 		}
 
+		if req.CategoryID != nil {
+			var count int64
+			db.Model(&models.ProductCategory{}).Where("id = ?", *req.CategoryID).Count(&count)
+			if count == 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Product category not found"})
+			}
+		}
+
 		err = db.Transaction(func(tx *gorm.DB) error {
 			// 1. Ambil produk yang ada
 			var existingProduct models.Product
@@ -228,6 +267,7 @@ func UpdateProduct(db *gorm.DB) fiber.Handler {
 			// 2. Update detail produk
 			existingProduct.Name = req.Name
 			existingProduct.Price = float64(req.Price)
+			existingProduct.CategoryID = req.CategoryID
 			if err := tx.Save(&existingProduct).Error; err != nil {
 				return err
 			}
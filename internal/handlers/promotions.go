@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"math/big"
+	"regexp"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PromotionRequest defines the structure for creating/updating a promotion
+type PromotionRequest struct {
+	Name           string                `json:"name" validate:"required"`
+	Type           models.PromotionType  `json:"type"`
+	Value          float64               `json:"value"`
+	BuyQuantity    int                   `json:"buy_quantity"`
+	GetQuantity    int                   `json:"get_quantity"`
+	Scope          models.PromotionScope `json:"scope"`
+	ProductID      *uint                 `json:"product_id"`
+	CategoryID     *uint                 `json:"category_id"`
+	MinSubtotal    float64               `json:"min_subtotal"`
+	AutoApply      bool                  `json:"auto_apply"`
+	IsActive       *bool                 `json:"is_active"` // default true
+	StartsAt       *time.Time            `json:"starts_at"`
+	EndsAt         *time.Time            `json:"ends_at"`
+	DailyStartTime string                `json:"daily_start_time"` // HH:MM
+	DailyEndTime   string                `json:"daily_end_time"`   // HH:MM
+}
+
+// VoucherRequest defines the body for creating vouchers.
+// Code kosong berarti kode dibuat otomatis; Quantity > 1 membuat banyak voucher sekaligus.
+type VoucherRequest struct {
+	PromotionID uint       `json:"promotion_id"`
+	Code        string     `json:"code"`
+	Quantity    int        `json:"quantity"`
+	MaxUses     *int       `json:"max_uses"` // default 1 (sekali pakai), 0 = tanpa batas
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// UpdateVoucherRequest defines the body for updating a voucher
+type UpdateVoucherRequest struct {
+	MaxUses   int        `json:"max_uses"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	IsActive  bool       `json:"is_active"`
+}
+
+var clockPattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// toPromotion validates the request and turns it into a promotion
+func (req PromotionRequest) toPromotion(db *gorm.DB) (models.Promotion, error) {
+	if req.Name == "" {
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+	if req.Scope == "" {
+		req.Scope = models.PromotionScopeOrder
+	}
+
+	switch req.Type {
+	case models.PromotionPercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Percentage value must be between 0 and 100")
+		}
+	case models.PromotionFixed:
+		if req.Value <= 0 {
+			return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Fixed discount value must be greater than zero")
+		}
+	case models.PromotionBuyXGetY:
+		if req.BuyQuantity <= 0 || req.GetQuantity <= 0 {
+			return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "buy_quantity and get_quantity must be greater than zero")
+		}
+	default:
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Invalid type, use percentage, fixed or buy_x_get_y")
+	}
+
+	promotion := models.Promotion{
+		Name:           req.Name,
+		Type:           req.Type,
+		Value:          req.Value,
+		BuyQuantity:    req.BuyQuantity,
+		GetQuantity:    req.GetQuantity,
+		Scope:          req.Scope,
+		MinSubtotal:    req.MinSubtotal,
+		AutoApply:      req.AutoApply,
+		IsActive:       req.IsActive == nil || *req.IsActive,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		DailyStartTime: req.DailyStartTime,
+		DailyEndTime:   req.DailyEndTime,
+	}
+
+	switch req.Scope {
+	case models.PromotionScopeOrder:
+	case models.PromotionScopeProduct:
+		var count int64
+		if req.ProductID != nil {
+			db.Model(&models.Product{}).Where("id = ?", *req.ProductID).Count(&count)
+		}
+		if count == 0 {
+			return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Product not found for product scope")
+		}
+		promotion.ProductID = req.ProductID
+	case models.PromotionScopeCategory:
+		var count int64
+		if req.CategoryID != nil {
+			db.Model(&models.ProductCategory{}).Where("id = ?", *req.CategoryID).Count(&count)
+		}
+		if count == 0 {
+			return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Product category not found for category scope")
+		}
+		promotion.CategoryID = req.CategoryID
+	default:
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Invalid scope, use order, product or category")
+	}
+
+	if req.MinSubtotal < 0 {
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "min_subtotal cannot be negative")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && req.EndsAt.Before(*req.StartsAt) {
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "ends_at must be after starts_at")
+	}
+	if (req.DailyStartTime == "") != (req.DailyEndTime == "") {
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "daily_start_time and daily_end_time must be set together")
+	}
+	if req.DailyStartTime != "" && (!clockPattern.MatchString(req.DailyStartTime) || !clockPattern.MatchString(req.DailyEndTime)) {
+		return models.Promotion{}, fiber.NewError(fiber.StatusBadRequest, "Daily time window must use HH:MM format")
+	}
+	return promotion, nil
+}
+
+// GetPromotions handles fetching all promotions. Gunakan ?active=true untuk yang aktif saja.
+func GetPromotions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Order("id desc")
+		if c.QueryBool("active") {
+			query = query.Where("is_active = ?", true)
+		}
+
+		var promotions []models.Promotion
+		if err := query.Find(&promotions).Error; err != nil {
+			return respondError(c, err, "Failed to fetch promotions")
+		}
+		return c.JSON(promotions)
+	}
+}
+
+// CreatePromotion handles creating a new promotion
+func CreatePromotion(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req PromotionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		promotion, err := req.toPromotion(db)
+		if err != nil {
+			return respondError(c, err, "Invalid promotion")
+		}
+		// Select("*") supaya is_active=false tetap tersimpan (bukan diganti default DB)
+		if err := db.Select("*").Omit("id", "created_at", "updated_at").Create(&promotion).Error; err != nil {
+			return respondError(c, err, "Failed to create promotion")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(promotion)
+	}
+}
+
+// UpdatePromotion handles updating an existing promotion
+func UpdatePromotion(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid promotion ID"})
+		}
+
+		var req PromotionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var existing models.Promotion
+		if err := db.First(&existing, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Promotion not found"})
+		}
+
+		promotion, err := req.toPromotion(db)
+		if err != nil {
+			return respondError(c, err, "Invalid promotion")
+		}
+		promotion.ID = existing.ID
+		promotion.CreatedAt = existing.CreatedAt
+		promotion.UpdatedAt = time.Now()
+		if err := db.Save(&promotion).Error; err != nil {
+			return respondError(c, err, "Failed to update promotion")
+		}
+
+		return c.JSON(promotion)
+	}
+}
+
+// DeletePromotion handles deleting a promotion and its vouchers.
+// Diskon yang sudah tercatat di transaksi tetap ada (nama promo disimpan sebagai snapshot).
+func DeletePromotion(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid promotion ID"})
+		}
+
+		result := db.Delete(&models.Promotion{}, id)
+		if result.Error != nil {
+			return respondError(c, result.Error, "Failed to delete promotion")
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Promotion not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Promotion deleted successfully"})
+	}
+}
+
+// GetVouchers handles listing vouchers, filterable by promotion_id and code
+func GetVouchers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Preload("Promotion").Order("id desc")
+		if promotionID := c.QueryInt("promotion_id"); promotionID > 0 {
+			query = query.Where("promotion_id = ?", promotionID)
+		}
+		if code := c.Query("code"); code != "" {
+			query = query.Where("code = ?", services.NormalizeVoucherCode(code))
+		}
+
+		var vouchers []models.Voucher
+		if err := query.Find(&vouchers).Error; err != nil {
+			return respondError(c, err, "Failed to fetch vouchers")
+		}
+		return c.JSON(vouchers)
+	}
+}
+
+// CreateVouchers handles creating one voucher with a given code, or a batch of
+// vouchers with generated codes
+func CreateVouchers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req VoucherRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var promotion models.Promotion
+		if err := db.First(&promotion, req.PromotionID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Promotion not found"})
+		}
+
+		maxUses := 1
+		if req.MaxUses != nil {
+			maxUses = *req.MaxUses
+		}
+		if maxUses < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "max_uses cannot be negative"})
+		}
+		if req.Quantity <= 0 {
+			req.Quantity = 1
+		}
+		if req.Quantity > 500 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cannot create more than 500 vouchers at once"})
+		}
+		code := services.NormalizeVoucherCode(req.Code)
+		if code != "" && req.Quantity > 1 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "A custom code can only be used for a single voucher"})
+		}
+
+		var vouchers []models.Voucher
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := 0; i < req.Quantity; i++ {
+				voucherCode := code
+				if voucherCode == "" {
+					generated, err := generateVoucherCode(tx)
+					if err != nil {
+						return err
+					}
+					voucherCode = generated
+				}
+
+				var count int64
+				tx.Model(&models.Voucher{}).Where("code = ?", voucherCode).Count(&count)
+				if count > 0 {
+					return fiber.NewError(fiber.StatusConflict, "Voucher code already exists")
+				}
+
+				voucher := models.Voucher{
+					PromotionID: promotion.ID,
+					Code:        voucherCode,
+					MaxUses:     maxUses,
+					ExpiresAt:   req.ExpiresAt,
+					IsActive:    true,
+				}
+				// Select("*") supaya max_uses=0 (tanpa batas) tidak diganti default DB
+				if err := tx.Select("*").Omit("id", "created_at").Create(&voucher).Error; err != nil {
+					return err
+				}
+				vouchers = append(vouchers, voucher)
+			}
+			return nil
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to create vouchers")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(vouchers)
+	}
+}
+
+// UpdateVoucher handles changing the usage limit, expiry or active flag of a voucher
+func UpdateVoucher(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid voucher ID"})
+		}
+
+		var req UpdateVoucherRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.MaxUses < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "max_uses cannot be negative"})
+		}
+
+		var voucher models.Voucher
+		if err := db.First(&voucher, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Voucher not found"})
+		}
+
+		voucher.MaxUses = req.MaxUses
+		voucher.ExpiresAt = req.ExpiresAt
+		voucher.IsActive = req.IsActive
+		if err := db.Save(&voucher).Error; err != nil {
+			return respondError(c, err, "Failed to update voucher")
+		}
+
+		return c.JSON(voucher)
+	}
+}
+
+// DeleteVoucher handles deleting a voucher that has never been used.
+// Voucher yang sudah dipakai cukup dinonaktifkan supaya riwayatnya tetap ada.
+func DeleteVoucher(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid voucher ID"})
+		}
+
+		var usedCount int64
+		db.Model(&models.TransactionDiscount{}).Where("voucher_id = ?", id).Count(&usedCount)
+		if usedCount > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Cannot delete a voucher that has been used, deactivate it instead."})
+		}
+
+		result := db.Delete(&models.Voucher{}, id)
+		if result.Error != nil {
+			return respondError(c, result.Error, "Failed to delete voucher")
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Voucher not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Voucher deleted successfully"})
+	}
+}
+
+// voucherAlphabet tanpa karakter yang mudah tertukar (0/O, 1/I)
+const voucherAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// generateVoucherCode creates a random 8 character code that is not in use yet
+func generateVoucherCode(db *gorm.DB) (string, error) {
+	for {
+		code, err := randomCode(8)
+		if err != nil {
+			return "", err
+		}
+
+		var count int64
+		db.Model(&models.Voucher{}).Where("code = ?", code).Count(&count)
+		if count == 0 {
+			return code, nil
+		}
+	}
+}
+
+// randomCode returns a random code of the given length from voucherAlphabet
+func randomCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(voucherAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = voucherAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
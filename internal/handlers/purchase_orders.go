@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"math"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// roundQuantity rounds a purchased quantity to the precision of the quantity
+// columns (NUMERIC(12,2)), supaya 0.1 + 0.2 dibandingkan sebagai 0.3
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*100) / 100
+}
+
+// PurchaseOrderRequest defines the body for creating a purchase order
+type PurchaseOrderRequest struct {
+	SupplierID uint       `json:"supplier_id" validate:"required"`
+	OrderDate  *time.Time `json:"order_date"`
+	Notes      string     `json:"notes"`
+	Lines      []struct {
+		InventoryItemID uint    `json:"inventory_item_id"`
+		Quantity        float64 `json:"quantity"`
+		UnitPrice       float64 `json:"unit_price"`
+	} `json:"lines"`
+}
+
+// ReceivePurchaseOrderRequest defines the body for receiving goods.
+// UnitPrice opsional; jika 0 dipakai harga di baris PO.
+type ReceivePurchaseOrderRequest struct {
+	InvoiceNumber string `json:"invoice_number"`
+	Notes         string `json:"notes"`
+	Items         []struct {
+		LineID    uint    `json:"line_id"`
+		Quantity  float64 `json:"quantity"`
+		UnitPrice float64 `json:"unit_price"`
+	} `json:"items"`
+}
+
+// GetPurchaseOrders handles listing purchase orders, filterable by status, supplier_id and order date
+func GetPurchaseOrders(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		query := db.Preload("Supplier").Preload("Lines.InventoryItem")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if supplierID := c.QueryInt("supplier_id"); supplierID > 0 {
+			query = query.Where("supplier_id = ?", supplierID)
+		}
+		query = whereDateRange(query, "order_date", startDate, endDate)
+
+		var orders []models.PurchaseOrder
+		if err := query.Order("order_date desc, id desc").Find(&orders).Error; err != nil {
+			return respondError(c, err, "Failed to fetch purchase orders")
+		}
+		return c.JSON(orders)
+	}
+}
+
+// GetPurchaseOrder handles fetching a single purchase order with its lines and receipts
+func GetPurchaseOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid purchase order ID"})
+		}
+
+		var order models.PurchaseOrder
+		err = db.Preload("Supplier").Preload("Lines.InventoryItem").Preload("Receipts.Items").First(&order, id).Error
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Purchase order not found"})
+		}
+		return c.JSON(order)
+	}
+}
+
+// CreatePurchaseOrder handles creating a purchase order with its lines
+func CreatePurchaseOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req PurchaseOrderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if len(req.Lines) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Purchase order must have at least one line"})
+		}
+
+		var supplier models.Supplier
+		if err := db.First(&supplier, req.SupplierID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Supplier not found"})
+		}
+
+		order := models.PurchaseOrder{
+			SupplierID:  supplier.ID,
+			Status:      models.PurchaseOrderOrdered,
+			OrderDate:   time.Now(),
+			Notes:       req.Notes,
+			CreatedByID: userID,
+		}
+		if req.OrderDate != nil {
+			order.OrderDate = *req.OrderDate
+		}
+
+		for _, line := range req.Lines {
+			if line.Quantity <= 0 || line.UnitPrice < 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Line quantity must be greater than zero and unit price cannot be negative"})
+			}
+			var count int64
+			db.Model(&models.InventoryItem{}).Where("id = ?", line.InventoryItemID).Count(&count)
+			if count == 0 {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Inventory item not found"})
+			}
+
+			order.TotalAmount += line.Quantity * line.UnitPrice
+			order.Lines = append(order.Lines, models.PurchaseOrderLine{
+				InventoryItemID: line.InventoryItemID,
+				QuantityOrdered: line.Quantity,
+				UnitPrice:       line.UnitPrice,
+			})
+		}
+
+		if err := db.Create(&order).Error; err != nil {
+			return respondError(c, err, "Failed to create purchase order")
+		}
+
+		order.Supplier = supplier
+		return c.Status(fiber.StatusCreated).JSON(order)
+	}
+}
+
+// ReceivePurchaseOrder handles receiving all or part of a purchase order.
+// Barang yang diterima langsung masuk stok sebagai movement "purchase".
+func ReceivePurchaseOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid purchase order ID"})
+		}
+
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req ReceivePurchaseOrderRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var receipt models.PurchaseReceipt
+		var stockItems []models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var order models.PurchaseOrder
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Purchase order not found")
+			}
+			if order.Status != models.PurchaseOrderOrdered && order.Status != models.PurchaseOrderPartiallyReceived {
+				return fiber.NewError(fiber.StatusConflict, "Purchase order is already "+string(order.Status))
+			}
+
+			var lines []models.PurchaseOrderLine
+			if err := tx.Where("purchase_order_id = ?", order.ID).Find(&lines).Error; err != nil {
+				return err
+			}
+			linesByID := make(map[uint]*models.PurchaseOrderLine, len(lines))
+			for i := range lines {
+				linesByID[lines[i].ID] = &lines[i]
+			}
+
+			// Tanpa items berarti terima semua sisa barang
+			type receiveLine struct {
+				line      *models.PurchaseOrderLine
+				quantity  float64
+				unitPrice float64
+			}
+			var toReceive []receiveLine
+			if len(req.Items) == 0 {
+				for i := range lines {
+					if remaining := roundQuantity(lines[i].QuantityOrdered - lines[i].QuantityReceived); remaining > 0 {
+						toReceive = append(toReceive, receiveLine{&lines[i], remaining, lines[i].UnitPrice})
+					}
+				}
+			}
+			for _, item := range req.Items {
+				line, ok := linesByID[item.LineID]
+				if !ok {
+					return fiber.NewError(fiber.StatusBadRequest, "Line does not belong to this purchase order")
+				}
+				quantity := roundQuantity(item.Quantity)
+				if quantity <= 0 || item.UnitPrice < 0 {
+					return fiber.NewError(fiber.StatusBadRequest, "Received quantity must be greater than zero and unit price cannot be negative")
+				}
+				unitPrice := item.UnitPrice
+				if unitPrice == 0 {
+					unitPrice = line.UnitPrice
+				}
+				toReceive = append(toReceive, receiveLine{line, quantity, unitPrice})
+			}
+			if len(toReceive) == 0 {
+				return fiber.NewError(fiber.StatusConflict, "Nothing left to receive on this purchase order")
+			}
+
+			receipt = models.PurchaseReceipt{
+				PurchaseOrderID: order.ID,
+				InvoiceNumber:   req.InvoiceNumber,
+				UserID:          userID,
+				Notes:           req.Notes,
+			}
+			movement := services.MovementInfo{
+				Type:          models.StockMovementPurchase,
+				ReferenceType: models.ReferencePurchaseOrder,
+				ReferenceID:   &order.ID,
+				UserID:        &userID,
+				Note:          req.InvoiceNumber,
+			}
+			var stockChanges []services.StockChange
+			for _, r := range toReceive {
+				r.line.QuantityReceived = roundQuantity(r.line.QuantityReceived + r.quantity)
+				if r.line.QuantityReceived > roundQuantity(r.line.QuantityOrdered) {
+					return fiber.NewError(fiber.StatusBadRequest, "Received quantity exceeds the quantity ordered")
+				}
+				if err := tx.Model(r.line).Update("quantity_received", r.line.QuantityReceived).Error; err != nil {
+					return err
+				}
+
+				receipt.Amount += r.quantity * r.unitPrice
+				receipt.Items = append(receipt.Items, models.PurchaseReceiptItem{
+					PurchaseOrderLineID: r.line.ID,
+					InventoryItemID:     r.line.InventoryItemID,
+					Quantity:            r.quantity,
+					UnitPrice:           r.unitPrice,
+				})
+				stockChanges = append(stockChanges, services.StockChange{
+					InventoryItemID: r.line.InventoryItemID,
+					Delta:           r.quantity,
+					UnitCost:        r.unitPrice,
+					MovementInfo:    movement,
+				})
+			}
+
+			if err := tx.Create(&receipt).Error; err != nil {
+				return err
+			}
+			updated, err := services.NewStockService(tx).Apply(stockChanges)
+			if err != nil {
+				return err
+			}
+			stockItems = updated
+
+			fullyReceived := true
+			for _, line := range lines {
+				if roundQuantity(line.QuantityReceived) < roundQuantity(line.QuantityOrdered) {
+					fullyReceived = false
+					break
+				}
+			}
+			order.ReceivedAmount += receipt.Amount
+			order.Status = models.PurchaseOrderPartiallyReceived
+			if fullyReceived {
+				order.Status = models.PurchaseOrderReceived
+			}
+			return tx.Model(&order).Updates(map[string]interface{}{
+				"status":          order.Status,
+				"received_amount": order.ReceivedAmount,
+			}).Error
+		})
+
+		if err != nil {
+			return respondError(c, err, "Failed to receive purchase order")
+		}
+		services.Events.PublishStockLevels(stockItems)
+
+		return c.Status(fiber.StatusCreated).JSON(receipt)
+	}
+}
+
+// CancelPurchaseOrder handles cancelling a purchase order that has not been received
+func CancelPurchaseOrder(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid purchase order ID"})
+		}
+
+		result := db.Model(&models.PurchaseOrder{}).
+			Where("id = ? AND status = ?", id, models.PurchaseOrderOrdered).
+			Update("status", models.PurchaseOrderCancelled)
+		if result.Error != nil {
+			return respondError(c, result.Error, "Failed to cancel purchase order")
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Only purchase orders that have not been received can be cancelled"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Purchase order cancelled successfully"})
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoidRequest defines the body for voiding a transaction
+type VoidRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// RefundRequest defines the body for refunding a transaction.
+// Items kosong berarti refund penuh untuk semua item yang belum di-refund.
+// PaymentMethod kosong berarti dikembalikan tunai jika transaksi dibayar (sebagian) tunai,
+// selain itu lewat metode pembayaran pertama transaksi.
+type RefundRequest struct {
+	Reason        string `json:"reason" validate:"required"`
+	PaymentMethod string `json:"payment_method"`
+	Items         []struct {
+		TransactionItemID uint `json:"transaction_item_id"`
+		Quantity          int  `json:"quantity"`
+	} `json:"items"`
+}
+
+// VoidTransaction cancels a mistaken sale while its shift is still open
+// and puts the deducted ingredients back into stock
+func VoidTransaction(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+		}
+
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req VoidRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Reason == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Reason is required"})
+		}
+
+		var transaction models.Transaction
+		var stockItems []models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+			}
+
+			if transaction.Status != models.TransactionStatusCompleted {
+				return fiber.NewError(fiber.StatusConflict, "Only completed transactions without refunds can be voided")
+			}
+
+			// Void hanya boleh selama shift transaksi masih buka, selebihnya harus lewat refund
+			var shift models.Shift
+			if transaction.ShiftID == nil || tx.First(&shift, *transaction.ShiftID).Error != nil || shift.Status != models.ShiftStatusOpen {
+				return fiber.NewError(fiber.StatusConflict, "Transaction can only be voided during the same shift, use a refund instead")
+			}
+
+			var items []models.TransactionItem
+			if err := tx.Where("transaction_id = ?", transaction.ID).Find(&items).Error; err != nil {
+				return err
+			}
+			stock := services.NewStockService(tx)
+			movement := services.MovementInfo{
+				Type:          models.StockMovementVoid,
+				ReferenceType: models.ReferenceTransaction,
+				ReferenceID:   &transaction.ID,
+				UserID:        &userID,
+				Note:          req.Reason,
+			}
+			var stockChanges []services.StockChange
+			for _, item := range items {
+				changes, err := itemStockChanges(stock, item, item.Quantity, 1, movement)
+				if err != nil {
+					return err
+				}
+				stockChanges = append(stockChanges, changes...)
+			}
+			updated, err := stock.Apply(stockChanges)
+			if err != nil {
+				return err
+			}
+			stockItems = updated
+
+			// Voucher yang dipakai bisa digunakan lagi karena penjualannya dibatalkan
+			var voucherIDs []uint
+			if err := tx.Model(&models.TransactionDiscount{}).
+				Where("transaction_id = ? AND voucher_id IS NOT NULL", transaction.ID).
+				Pluck("voucher_id", &voucherIDs).Error; err != nil {
+				return err
+			}
+			promotions := services.NewPromotionService(tx)
+			for _, voucherID := range voucherIDs {
+				if err := promotions.ReleaseVoucher(voucherID); err != nil {
+					return err
+				}
+			}
+
+			// Poin yang ditukar dikembalikan dan poin yang didapat ditarik lagi
+			if err := reverseSalePoints(tx, transaction, models.LoyaltyEntryVoid, 0, 1, userID, "Void: "+req.Reason); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			transaction.Status = models.TransactionStatusVoided
+			transaction.VoidedAt = &now
+			transaction.VoidedByID = &userID
+			transaction.VoidReason = req.Reason
+			return tx.Save(&transaction).Error
+		})
+
+		if err != nil {
+			return respondError(c, err, "Failed to void transaction")
+		}
+		services.Events.PublishStockLevels(stockItems)
+		publishOrderEvent(db, transaction.ID)
+
+		return c.JSON(transaction)
+	}
+}
+
+// RefundTransaction refunds all or part of a transaction and restores the
+// recipe ingredients of the refunded items
+func RefundTransaction(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+		}
+
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req RefundRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Reason == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Reason is required"})
+		}
+
+		var refund models.Refund
+		var stockItems []models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var transaction models.Transaction
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Transaction not found")
+			}
+
+			if transaction.Status == models.TransactionStatusVoided || transaction.Status == models.TransactionStatusRefunded {
+				return fiber.NewError(fiber.StatusConflict, "Transaction has already been "+string(transaction.Status))
+			}
+
+			var items []models.TransactionItem
+			if err := tx.Where("transaction_id = ?", transaction.ID).Find(&items).Error; err != nil {
+				return err
+			}
+			itemsByID := make(map[uint]*models.TransactionItem, len(items))
+			for i := range items {
+				itemsByID[items[i].ID] = &items[i]
+			}
+
+			// Tentukan jumlah yang di-refund per item
+			quantities := make(map[uint]int)
+			if len(req.Items) == 0 {
+				for _, item := range items {
+					if remaining := item.Quantity - item.RefundedQuantity; remaining > 0 {
+						quantities[item.ID] = remaining
+					}
+				}
+			}
+			for _, reqItem := range req.Items {
+				item, ok := itemsByID[reqItem.TransactionItemID]
+				if !ok {
+					return fiber.NewError(fiber.StatusBadRequest, "Transaction item does not belong to this transaction")
+				}
+				if reqItem.Quantity <= 0 {
+					return fiber.NewError(fiber.StatusBadRequest, "Refund quantity must be greater than zero")
+				}
+				quantities[item.ID] += reqItem.Quantity
+				if quantities[item.ID] > item.Quantity-item.RefundedQuantity {
+					return fiber.NewError(fiber.StatusBadRequest, "Refund quantity exceeds the quantity left to refund")
+				}
+			}
+			if len(quantities) == 0 {
+				return fiber.NewError(fiber.StatusConflict, "Nothing left to refund on this transaction")
+			}
+
+			chargeRatio := 1.0
+			if net := transaction.Subtotal - transaction.DiscountAmount; net > 0 {
+				chargeRatio = transaction.TotalAmount / net
+			}
+
+			paymentMethod, err := refundPaymentMethod(tx, transaction.ID, req.PaymentMethod)
+			if err != nil {
+				return err
+			}
+
+			refund = models.Refund{
+				TransactionID: transaction.ID,
+				PaymentMethod: paymentMethod,
+				Reason:        req.Reason,
+				UserID:        userID,
+			}
+			// Uang refund keluar dari laci shift yang sedang dibuka (jika ada)
+			if shift, err := findOpenShift(tx, userID); err == nil {
+				refund.ShiftID = &shift.ID
+			}
+			stock := services.NewStockService(tx)
+			movement := services.MovementInfo{
+				Type:          models.StockMovementRefund,
+				ReferenceType: models.ReferenceTransaction,
+				ReferenceID:   &transaction.ID,
+				UserID:        &userID,
+				Note:          req.Reason,
+			}
+			var stockChanges []services.StockChange
+			for _, item := range items {
+				qty, ok := quantities[item.ID]
+				if !ok {
+					continue
+				}
+				// Refund sebesar yang benar-benar dibayar: setelah diskon, termasuk
+				// service charge & pajak eksklusif secara proporsional
+				amount := services.RoundMoney((item.Subtotal - item.DiscountAmount) / float64(item.Quantity) * float64(qty) * chargeRatio)
+				refund.Amount += amount
+				refund.Items = append(refund.Items, models.RefundItem{
+					TransactionItemID: item.ID,
+					ProductID:         item.ProductID,
+					Quantity:          qty,
+					Amount:            amount,
+				})
+
+				if err := tx.Model(&models.TransactionItem{}).Where("id = ?", item.ID).
+					Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", qty)).Error; err != nil {
+					return err
+				}
+				changes, err := itemStockChanges(stock, item, qty, 1, movement)
+				if err != nil {
+					return err
+				}
+				stockChanges = append(stockChanges, changes...)
+			}
+			updated, err := stock.Apply(stockChanges)
+			if err != nil {
+				return err
+			}
+			stockItems = updated
+
+			// Refund penuh jika semua item sudah dikembalikan
+			fullyRefunded := true
+			for _, item := range items {
+				if item.RefundedQuantity+quantities[item.ID] < item.Quantity {
+					fullyRefunded = false
+					break
+				}
+			}
+			transaction.Status = models.TransactionStatusPartiallyRefunded
+			if fullyRefunded {
+				transaction.Status = models.TransactionStatusRefunded
+				// Sisa pembulatan ikut di refund terakhir supaya total refund = total bayar
+				refund.Amount = transaction.TotalAmount - transaction.RefundedAmount
+			}
+
+			if err := tx.Create(&refund).Error; err != nil {
+				return err
+			}
+
+			// Poin dibalik sebanding dengan bagian transaksi yang di-refund
+			before, after := 0.0, 1.0
+			if transaction.TotalAmount > 0 {
+				before = transaction.RefundedAmount / transaction.TotalAmount
+				if !fullyRefunded {
+					after = (transaction.RefundedAmount + refund.Amount) / transaction.TotalAmount
+				}
+			}
+			if err := reverseSalePoints(tx, transaction, models.LoyaltyEntryRefund, before, after, userID, "Refund: "+req.Reason); err != nil {
+				return err
+			}
+			transaction.RefundedAmount += refund.Amount
+			return tx.Save(&transaction).Error
+		})
+
+		if err != nil {
+			return respondError(c, err, "Failed to refund transaction")
+		}
+		services.Events.PublishStockLevels(stockItems)
+
+		return c.Status(fiber.StatusCreated).JSON(refund)
+	}
+}
+
+// refundPaymentMethod decides which payment method a refund is paid out with
+func refundPaymentMethod(tx *gorm.DB, transactionID uint, requested string) (string, error) {
+	if code := normalizePaymentCode(requested); code != "" {
+		var method models.PaymentMethod
+		if err := tx.Where("code = ?", code).First(&method).Error; err != nil {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Unknown payment method: "+requested)
+		}
+		return method.Code, nil
+	}
+
+	var payments []models.TransactionPayment
+	if err := tx.Where("transaction_id = ?", transactionID).Order("id").Find(&payments).Error; err != nil {
+		return "", err
+	}
+	if len(payments) == 0 {
+		return cashDrawer, nil
+	}
+	cashCodes, err := cashDrawerCodes(tx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range payments {
+		if cashCodes[p.PaymentMethod] {
+			return p.PaymentMethod, nil
+		}
+	}
+	return payments[0].PaymentMethod, nil
+}
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// parseDateRange reads the start_date and end_date query parameters (YYYY-MM-DD).
+// end_date dibuat inklusif sampai akhir hari.
+func parseDateRange(c *fiber.Ctx) (startDate, endDate time.Time, err error) {
+	if s := c.Query("start_date"); s != "" {
+		startDate, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			return startDate, endDate, fiber.NewError(fiber.StatusBadRequest, "Invalid start_date format. Use YYYY-MM-DD")
+		}
+	}
+
+	if s := c.Query("end_date"); s != "" {
+		endDate, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			return startDate, endDate, fiber.NewError(fiber.StatusBadRequest, "Invalid end_date format. Use YYYY-MM-DD")
+		}
+		endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return startDate, endDate, nil
+}
+
+// whereDateRange applies the date range to the given column
+func whereDateRange(query *gorm.DB, column string, startDate, endDate time.Time) *gorm.DB {
+	if !startDate.IsZero() {
+		query = query.Where(column+" >= ?", startDate)
+	}
+	if !endDate.IsZero() {
+		query = query.Where(column+" <= ?", endDate)
+	}
+	return query
+}
+
+// refundShareSQL is the share of transaction t returned by refund r, e.g. 0.25.
+// Laporan mengurangkan bagian refund pada periode refund dilakukan (r.created_at),
+// bukan pada periode penjualan, supaya periode yang sudah dilaporkan tidak berubah.
+const refundShareSQL = "(case when t.total_amount > 0 then r.amount / t.total_amount else 0 end)"
+
+// ProductSalesRow is one product line in the sales report
+type ProductSalesRow struct {
+	ProductID     uint    `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	Quantity      int     `json:"quantity"`
+	Discounts     float64 `json:"discounts"`
+	Revenue       float64 `json:"revenue"` // setelah diskon
+	COGS          float64 `json:"cogs" gorm:"column:cogs"`
+	GrossMargin   float64 `json:"gross_margin" gorm:"-"`
+	MarginPercent float64 `json:"margin_percent" gorm:"-"`
+}
+
+// SalesReportResponse defines the sales, margin and COGS report
+type SalesReportResponse struct {
+	Discounts     float64           `json:"discounts"`
+	Revenue       float64           `json:"revenue"`
+	COGS          float64           `json:"cogs"`
+	GrossMargin   float64           `json:"gross_margin"`
+	MarginPercent float64           `json:"margin_percent"`
+	Products      []ProductSalesRow `json:"products"`
+}
+
+// GetSalesReport reports sold quantity, revenue, COGS and margin per product.
+// Semua angka diambil dari snapshot di transaction_items, bukan dari tabel products.
+func GetSalesReport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		var rows []ProductSalesRow
+		query := db.Table("transaction_items ti").
+			Select(`ti.product_id, ti.product_name,
+				sum(ti.quantity - ti.refunded_quantity) as quantity,
+				sum(ti.discount_amount * (ti.quantity - ti.refunded_quantity) / ti.quantity) as discounts,
+				sum((ti.unit_price * ti.quantity - ti.discount_amount) * (ti.quantity - ti.refunded_quantity) / ti.quantity) as revenue,
+				sum(ti.unit_cost * (ti.quantity - ti.refunded_quantity)) as cogs`).
+			Joins("join transactions t on t.id = ti.transaction_id").
+			Where("t.status <> ?", models.TransactionStatusVoided).
+			Group("ti.product_id, ti.product_name").
+			Order("revenue desc")
+		query = whereDateRange(query, "t.transaction_time", startDate, endDate)
+		if err := query.Scan(&rows).Error; err != nil {
+			return respondError(c, err, "Failed to generate sales report")
+		}
+
+		response := SalesReportResponse{Products: rows}
+		for i := range response.Products {
+			row := &response.Products[i]
+			row.GrossMargin = row.Revenue - row.COGS
+			row.MarginPercent = marginPercent(row.Revenue, row.GrossMargin)
+			response.Discounts += row.Discounts
+			response.Revenue += row.Revenue
+			response.COGS += row.COGS
+		}
+		response.GrossMargin = response.Revenue - response.COGS
+		response.MarginPercent = marginPercent(response.Revenue, response.GrossMargin)
+
+		return c.JSON(response)
+	}
+}
+
+func marginPercent(revenue, margin float64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return margin / revenue * 100
+}
+
+// profitLossGroupings maps the group_by query value to a date_trunc field
+var profitLossGroupings = map[string]string{
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+// ProfitLossPeriod is one bucket (day, week or month) of the P&L report
+type ProfitLossPeriod struct {
+	Period           string  `json:"period"`
+	Refunds          float64 `json:"refunds"` // pendapatan yang dikembalikan di periode ini
+	Revenue          float64 `json:"revenue"` // setelah dikurangi refunds
+	COGS             float64 `json:"cogs"`
+	GrossProfit      float64 `json:"gross_profit"`
+	OperationalCosts float64 `json:"operational_costs"`
+	NetProfit        float64 `json:"net_profit"`
+}
+
+// CategoryAmount is a total per operational cost category
+type CategoryAmount struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
+// ProfitLossResponse defines the profit and loss report
+type ProfitLossResponse struct {
+	GroupBy          string             `json:"group_by"`
+	Refunds          float64            `json:"refunds"`
+	Revenue          float64            `json:"revenue"`
+	COGS             float64            `json:"cogs"`
+	GrossProfit      float64            `json:"gross_profit"`
+	GrossMargin      float64            `json:"gross_margin_percent"`
+	OperationalCosts float64            `json:"operational_costs"`
+	CostsByCategory  []CategoryAmount   `json:"operational_costs_by_category"`
+	NetProfit        float64            `json:"net_profit"`
+	Periods          []ProfitLossPeriod `json:"periods"`
+}
+
+// GetProfitLossReport reports revenue, COGS, gross profit, operational costs per
+// category and net profit for a date range, broken down by day, week or month.
+// Pendapatan dan HPP dihitung berdasarkan waktu transaksi, lalu refund (dan HPP
+// item yang dikembalikan) dikurangkan pada periode refund dilakukan, sama seperti
+// laporan keuangan. HPP memakai snapshot unit_cost saat penjualan. Pendapatan
+// tidak termasuk pajak karena pajak disetor ke pemerintah.
+func GetProfitLossReport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		groupBy := c.Query("group_by", "day")
+		field, ok := profitLossGroupings[groupBy]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid group_by, use day, week or month"})
+		}
+
+		type periodAmount struct {
+			Period time.Time
+			Amount float64
+		}
+
+		var revenues []periodAmount
+		query := db.Model(&models.Transaction{}).
+			Select("date_trunc(?, transaction_time) as period, sum(total_amount - tax_amount) as amount", field).
+			Where("status <> ?", models.TransactionStatusVoided).
+			Group("period")
+		query = whereDateRange(query, "transaction_time", startDate, endDate)
+		if err := query.Scan(&revenues).Error; err != nil {
+			return respondError(c, err, "Failed to generate profit and loss report")
+		}
+
+		var refunds []periodAmount
+		query = db.Table("refunds r").
+			Select("date_trunc(?, r.created_at) as period, sum((t.total_amount - t.tax_amount) * "+refundShareSQL+") as amount", field).
+			Joins("join transactions t on t.id = r.transaction_id").
+			Group("period")
+		query = whereDateRange(query, "r.created_at", startDate, endDate)
+		if err := query.Scan(&refunds).Error; err != nil {
+			return respondError(c, err, "Failed to generate profit and loss report")
+		}
+
+		var cogs []periodAmount
+		query = db.Table("transaction_items ti").
+			Select("date_trunc(?, t.transaction_time) as period, sum(ti.unit_cost * ti.quantity) as amount", field).
+			Joins("join transactions t on t.id = ti.transaction_id").
+			Where("t.status <> ?", models.TransactionStatusVoided).
+			Group("period")
+		query = whereDateRange(query, "t.transaction_time", startDate, endDate)
+		if err := query.Scan(&cogs).Error; err != nil {
+			return respondError(c, err, "Failed to generate profit and loss report")
+		}
+
+		// Bahan dari item yang di-refund kembali ke stok, jadi HPP-nya dikurangkan
+		var refundedCOGS []periodAmount
+		query = db.Table("refund_items ri").
+			Select("date_trunc(?, r.created_at) as period, sum(ti.unit_cost * ri.quantity) as amount", field).
+			Joins("join refunds r on r.id = ri.refund_id").
+			Joins("join transaction_items ti on ti.id = ri.transaction_item_id").
+			Group("period")
+		query = whereDateRange(query, "r.created_at", startDate, endDate)
+		if err := query.Scan(&refundedCOGS).Error; err != nil {
+			return respondError(c, err, "Failed to generate profit and loss report")
+		}
+
+		var costs []struct {
+			Period   time.Time
+			Category string
+			Amount   float64
+		}
+		query = db.Model(&models.OperationalCost{}).
+			Select("date_trunc(?, date) as period, coalesce(nullif(category, ''), 'Lainnya') as category, sum(amount) as amount", field).
+			Group("period, 2")
+		query = whereDateRange(query, "date", startDate, endDate)
+		if err := query.Scan(&costs).Error; err != nil {
+			return respondError(c, err, "Failed to generate profit and loss report")
+		}
+
+		// Gabungkan semua angka ke dalam periode masing-masing
+		periods := make(map[string]*ProfitLossPeriod)
+		periodFor := func(t time.Time) *ProfitLossPeriod {
+			key := t.Format("2006-01-02")
+			if p, ok := periods[key]; ok {
+				return p
+			}
+			p := &ProfitLossPeriod{Period: key}
+			periods[key] = p
+			return p
+		}
+
+		response := ProfitLossResponse{GroupBy: groupBy}
+		for _, r := range revenues {
+			periodFor(r.Period).Revenue += r.Amount
+			response.Revenue += r.Amount
+		}
+		for _, r := range refunds {
+			p := periodFor(r.Period)
+			p.Refunds += r.Amount
+			p.Revenue -= r.Amount
+			response.Refunds += r.Amount
+			response.Revenue -= r.Amount
+		}
+		for _, r := range cogs {
+			periodFor(r.Period).COGS += r.Amount
+			response.COGS += r.Amount
+		}
+		for _, r := range refundedCOGS {
+			periodFor(r.Period).COGS -= r.Amount
+			response.COGS -= r.Amount
+		}
+		byCategory := make(map[string]float64)
+		for _, r := range costs {
+			periodFor(r.Period).OperationalCosts += r.Amount
+			byCategory[r.Category] += r.Amount
+			response.OperationalCosts += r.Amount
+		}
+
+		for _, p := range periods {
+			// Bagian refund dihitung proporsional, bulatkan supaya sama dengan laporan keuangan
+			p.Refunds = services.RoundMoney(p.Refunds)
+			p.Revenue = services.RoundMoney(p.Revenue)
+			p.GrossProfit = p.Revenue - p.COGS
+			p.NetProfit = p.GrossProfit - p.OperationalCosts
+			response.Periods = append(response.Periods, *p)
+		}
+		sort.Slice(response.Periods, func(i, j int) bool {
+			return response.Periods[i].Period < response.Periods[j].Period
+		})
+
+		for category, amount := range byCategory {
+			response.CostsByCategory = append(response.CostsByCategory, CategoryAmount{Category: category, Amount: amount})
+		}
+		sort.Slice(response.CostsByCategory, func(i, j int) bool {
+			return response.CostsByCategory[i].Amount > response.CostsByCategory[j].Amount
+		})
+
+		response.Refunds = services.RoundMoney(response.Refunds)
+		response.Revenue = services.RoundMoney(response.Revenue)
+		response.GrossProfit = response.Revenue - response.COGS
+		response.GrossMargin = marginPercent(response.Revenue, response.GrossProfit)
+		response.NetProfit = response.GrossProfit - response.OperationalCosts
+
+		return c.JSON(response)
+	}
+}
+
+// DiscountReportRow is the total discount given by one promotion
+type DiscountReportRow struct {
+	PromotionID  *uint   `json:"promotion_id"`
+	Name         string  `json:"name"`
+	Transactions int     `json:"transactions"`
+	VoucherUses  int     `json:"voucher_uses"`
+	Amount       float64 `json:"amount"`
+}
+
+// DiscountReportResponse defines the discount report
+type DiscountReportResponse struct {
+	TotalDiscounts float64             `json:"total_discounts"`
+	GrossSales     float64             `json:"gross_sales"`
+	DiscountRate   float64             `json:"discount_rate_percent"`
+	Promotions     []DiscountReportRow `json:"promotions"`
+}
+
+// GetDiscountReport reports the discounts given per promotion for a date range.
+// Transaksi yang di-void tidak dihitung.
+func GetDiscountReport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		var rows []DiscountReportRow
+		query := db.Table("transaction_discounts td").
+			Select(`td.promotion_id, td.name,
+				count(distinct td.transaction_id) as transactions,
+				count(td.voucher_id) as voucher_uses,
+				sum(td.amount) as amount`).
+			Joins("join transactions t on t.id = td.transaction_id").
+			Where("t.status <> ?", models.TransactionStatusVoided).
+			Group("td.promotion_id, td.name").
+			Order("amount desc")
+		query = whereDateRange(query, "t.transaction_time", startDate, endDate)
+		if err := query.Scan(&rows).Error; err != nil {
+			return respondError(c, err, "Failed to generate discount report")
+		}
+
+		response := DiscountReportResponse{Promotions: rows}
+		for _, row := range rows {
+			response.TotalDiscounts += row.Amount
+		}
+
+		query = db.Model(&models.Transaction{}).Where("status <> ?", models.TransactionStatusVoided)
+		query = whereDateRange(query, "transaction_time", startDate, endDate)
+		if err := query.Select("coalesce(sum(subtotal), 0)").Row().Scan(&response.GrossSales); err != nil {
+			return respondError(c, err, "Failed to generate discount report")
+		}
+		response.DiscountRate = marginPercent(response.GrossSales, response.TotalDiscounts)
+
+		return c.JSON(response)
+	}
+}
+
+// TaxReportRow is the total of one tax or service charge rule in a period
+type TaxReportRow struct {
+	Period         string            `json:"period"`
+	Name           string            `json:"name"`
+	Kind           models.ChargeKind `json:"kind"`
+	Rate           float64           `json:"rate"`
+	Inclusive      bool              `json:"inclusive"`
+	Transactions   int               `json:"transactions"`
+	Base           float64           `json:"base"`            // DPP setelah dikurangi refund di periode ini
+	Amount         float64           `json:"amount"`          // yang dipungut saat penjualan
+	RefundedAmount float64           `json:"refunded_amount"` // dikembalikan lewat refund di periode ini
+	NetAmount      float64           `json:"net_amount"`      // amount - refunded_amount
+}
+
+// TaxReportResponse defines the tax summary report used for filing
+type TaxReportResponse struct {
+	GroupBy        string         `json:"group_by"`
+	TaxBase        float64        `json:"tax_base"`
+	TaxAmount      float64        `json:"tax_amount"`
+	ServiceCharges float64        `json:"service_charges"`
+	Rows           []TaxReportRow `json:"rows"`
+}
+
+// GetTaxReport summarises tax and service charges per rule and per day, week
+// or month (default month, sesuai masa pajak). Transaksi void tidak dihitung.
+// Pajak dicatat pada periode penjualan dan bagian yang di-refund dikurangkan pada
+// periode refund, jadi masa pajak yang sudah dilaporkan tidak berubah.
+func GetTaxReport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		groupBy := c.Query("group_by", "month")
+		field, ok := profitLossGroupings[groupBy]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid group_by, use day, week or month"})
+		}
+
+		var sales []struct {
+			TaxReportRow
+			PeriodStart time.Time
+		}
+		query := db.Table("transaction_charges tc").
+			Select(`date_trunc(?, t.transaction_time) as period_start, tc.name, tc.kind, tc.rate, tc.inclusive,
+				count(distinct tc.transaction_id) as transactions,
+				sum(tc.base) as base,
+				sum(tc.amount) as amount`, field).
+			Joins("join transactions t on t.id = tc.transaction_id").
+			Where("t.status <> ?", models.TransactionStatusVoided).
+			Group("period_start, tc.name, tc.kind, tc.rate, tc.inclusive")
+		query = whereDateRange(query, "t.transaction_time", startDate, endDate)
+		if err := query.Scan(&sales).Error; err != nil {
+			return respondError(c, err, "Failed to generate tax report")
+		}
+
+		var refunds []struct {
+			TaxReportRow
+			PeriodStart  time.Time
+			RefundedBase float64
+		}
+		query = db.Table("refunds r").
+			Select(`date_trunc(?, r.created_at) as period_start, tc.name, tc.kind, tc.rate, tc.inclusive,
+				sum(tc.base * `+refundShareSQL+`) as refunded_base,
+				sum(tc.amount * `+refundShareSQL+`) as refunded_amount`, field).
+			Joins("join transactions t on t.id = r.transaction_id").
+			Joins("join transaction_charges tc on tc.transaction_id = t.id").
+			Group("period_start, tc.name, tc.kind, tc.rate, tc.inclusive")
+		query = whereDateRange(query, "r.created_at", startDate, endDate)
+		if err := query.Scan(&refunds).Error; err != nil {
+			return respondError(c, err, "Failed to generate tax report")
+		}
+
+		// Gabungkan penjualan dan refund per periode & aturan
+		rows := make(map[string]*TaxReportRow)
+		rowFor := func(period time.Time, r TaxReportRow) *TaxReportRow {
+			key := fmt.Sprintf("%s|%s|%s|%v|%v", period.Format("2006-01-02"), r.Name, r.Kind, r.Rate, r.Inclusive)
+			if row, ok := rows[key]; ok {
+				return row
+			}
+			row := &TaxReportRow{Period: period.Format("2006-01-02"), Name: r.Name, Kind: r.Kind, Rate: r.Rate, Inclusive: r.Inclusive}
+			rows[key] = row
+			return row
+		}
+		for _, r := range sales {
+			row := rowFor(r.PeriodStart, r.TaxReportRow)
+			row.Transactions += r.Transactions
+			row.Base += r.Base
+			row.Amount += r.Amount
+		}
+		for _, r := range refunds {
+			row := rowFor(r.PeriodStart, r.TaxReportRow)
+			row.Base -= r.RefundedBase
+			row.RefundedAmount += r.RefundedAmount
+		}
+
+		response := TaxReportResponse{GroupBy: groupBy, Rows: []TaxReportRow{}}
+		for _, row := range rows {
+			row.Base = services.RoundMoney(row.Base)
+			row.RefundedAmount = services.RoundMoney(row.RefundedAmount)
+			row.NetAmount = services.RoundMoney(row.Amount - row.RefundedAmount)
+			if row.Kind == models.ChargeKindTax {
+				response.TaxBase += row.Base
+				response.TaxAmount += row.NetAmount
+			} else {
+				response.ServiceCharges += row.NetAmount
+			}
+			response.Rows = append(response.Rows, *row)
+		}
+		sort.Slice(response.Rows, func(i, j int) bool {
+			a, b := response.Rows[i], response.Rows[j]
+			if a.Period != b.Period {
+				return a.Period < b.Period
+			}
+			if a.Kind != b.Kind {
+				return a.Kind > b.Kind
+			}
+			return a.Name < b.Name
+		})
+
+		return c.JSON(response)
+	}
+}
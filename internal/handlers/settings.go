@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SettingRequest defines the body for updating a setting
+type SettingRequest struct {
+	Value string `json:"value"`
+}
+
+// settingValidators berisi setting yang boleh diubah beserta validasinya
+var settingValidators = map[string]func(string) bool{
+	services.SettingStockPolicy:   func(v string) bool { return models.StockPolicy(v).Valid() },
+	services.SettingStoreName:     func(v string) bool { return v != "" && len(v) <= 100 },
+	services.SettingStoreAddress:  func(v string) bool { return len(v) <= 500 },
+	services.SettingReceiptFooter: func(v string) bool { return len(v) <= 500 },
+
+	services.SettingLoyaltyEarnAmount: isNonNegativeNumber,
+	services.SettingLoyaltyPointValue: isNonNegativeNumber,
+	services.SettingLoyaltyMinRedeem:  isNonNegativeInteger,
+}
+
+func isNonNegativeNumber(v string) bool {
+	n, err := strconv.ParseFloat(v, 64)
+	return err == nil && n >= 0 && !math.IsInf(n, 0)
+}
+
+func isNonNegativeInteger(v string) bool {
+	n, err := strconv.Atoi(v)
+	return err == nil && n >= 0
+}
+
+// GetSettings handles fetching all global settings
+func GetSettings(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var settings []models.Setting
+		if err := db.Order("key").Find(&settings).Error; err != nil {
+			return respondError(c, err, "Failed to fetch settings")
+		}
+		return c.JSON(settings)
+	}
+}
+
+// UpdateSetting handles creating or updating a single global setting
+func UpdateSetting(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Params("key")
+		validate, ok := settingValidators[key]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown setting"})
+		}
+
+		var req SettingRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if !validate(req.Value) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid value for " + key})
+		}
+
+		setting, err := services.SetSetting(db, key, req.Value)
+		if err != nil {
+			return respondError(c, err, "Failed to update setting")
+		}
+		return c.JSON(setting)
+	}
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OpenShiftRequest defines the body for opening a cash register shift
+type OpenShiftRequest struct {
+	OpeningCash float64 `json:"opening_cash" validate:"gte=0"`
+	Notes       string  `json:"notes"`
+}
+
+// CloseShiftRequest defines the body for closing a shift.
+// Counted berisi uang yang dihitung per metode pembayaran, contoh {"cash": 650000, "qris": 120000}.
+type CloseShiftRequest struct {
+	Counted map[string]float64 `json:"counted"`
+	Notes   string             `json:"notes"`
+}
+
+// findOpenShift returns the open shift of the given cashier.
+// Row di-lock (FOR SHARE) supaya shift tidak bisa ditutup di tengah transaksi.
+func findOpenShift(tx *gorm.DB, userID uint) (*models.Shift, error) {
+	var shift models.Shift
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("user_id = ? AND status = ?", userID, models.ShiftStatusOpen).
+		First(&shift).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusConflict, "No open shift. Open a shift before making sales")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// OpenShift opens a new shift for the current cashier with an opening cash float
+func OpenShift(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req OpenShiftRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.OpeningCash < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Opening cash cannot be negative"})
+		}
+
+		var count int64
+		db.Model(&models.Shift{}).Where("user_id = ? AND status = ?", userID, models.ShiftStatusOpen).Count(&count)
+		if count > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "You already have an open shift"})
+		}
+
+		shift := models.Shift{
+			UserID:      userID,
+			Status:      models.ShiftStatusOpen,
+			OpeningCash: req.OpeningCash,
+			Notes:       req.Notes,
+		}
+		if err := db.Create(&shift).Error; err != nil {
+			return respondError(c, err, "Failed to open shift")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(shift)
+	}
+}
+
+// GetCurrentShift returns the open shift of the current cashier
+func GetCurrentShift(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var shift models.Shift
+		if err := db.Where("user_id = ? AND status = ?", userID, models.ShiftStatusOpen).First(&shift).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No open shift"})
+		}
+
+		return c.JSON(shift)
+	}
+}
+
+// GetShifts lists shifts, newest first, optionally filtered by opened_at date range
+func GetShifts(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		var shifts []models.Shift
+		query := whereDateRange(db.Preload("Payments"), "opened_at", startDate, endDate)
+		if err := query.Order("opened_at desc").Find(&shifts).Error; err != nil {
+			return respondError(c, err, "Failed to fetch shifts")
+		}
+
+		return c.JSON(shifts)
+	}
+}
+
+// GetShift returns a single shift with its payment breakdown
+func GetShift(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid shift ID"})
+		}
+
+		var shift models.Shift
+		if err := db.Preload("Payments").First(&shift, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shift not found"})
+		}
+
+		return c.JSON(shift)
+	}
+}
+
+// CloseShift closes a shift with the counted amounts and stores the
+// expected vs. counted variance per payment method
+func CloseShift(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid shift ID"})
+		}
+
+		userID, role, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var req CloseShiftRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var shift models.Shift
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shift, id).Error; err != nil {
+				return fiber.NewError(fiber.StatusNotFound, "Shift not found")
+			}
+			if shift.UserID != userID && role != models.RoleAdmin {
+				return fiber.NewError(fiber.StatusForbidden, "You can only close your own shift")
+			}
+			if shift.Status != models.ShiftStatusOpen {
+				return fiber.NewError(fiber.StatusConflict, "Shift is already closed")
+			}
+
+			cashCodes, err := cashDrawerCodes(tx)
+			if err != nil {
+				return err
+			}
+			expected, err := shiftExpectedAmounts(tx, &shift, cashCodes)
+			if err != nil {
+				return err
+			}
+
+			counted := make(map[string]float64, len(req.Counted))
+			for method, amount := range req.Counted {
+				counted[drawerKey(normalizePaymentCode(method), cashCodes)] += amount
+			}
+			if _, ok := counted[cashDrawer]; !ok {
+				return fiber.NewError(fiber.StatusBadRequest, "Counted cash is required to close a shift")
+			}
+
+			// Gabungkan metode yang diharapkan dan yang dihitung
+			methods := make(map[string]bool)
+			for method := range expected {
+				methods[method] = true
+			}
+			for method := range counted {
+				methods[method] = true
+			}
+
+			sorted := make([]string, 0, len(methods))
+			for method := range methods {
+				sorted = append(sorted, method)
+			}
+			sort.Strings(sorted)
+
+			shift.Payments = nil
+			shift.ExpectedCash, shift.CountedCash = 0, 0
+			for _, method := range sorted {
+				countedAmount, ok := counted[method]
+				if !ok && method != cashDrawer {
+					// Metode non-tunai yang tidak dihitung dianggap sesuai settlement
+					countedAmount = expected[method]
+				}
+				payment := models.ShiftPayment{
+					ShiftID:        shift.ID,
+					PaymentMethod:  method,
+					ExpectedAmount: expected[method],
+					CountedAmount:  countedAmount,
+					Variance:       countedAmount - expected[method],
+				}
+				shift.Payments = append(shift.Payments, payment)
+
+				if method == cashDrawer {
+					shift.ExpectedCash += payment.ExpectedAmount
+					shift.CountedCash += payment.CountedAmount
+				}
+			}
+
+			now := time.Now()
+			shift.CashVariance = shift.CountedCash - shift.ExpectedCash
+			shift.Status = models.ShiftStatusClosed
+			shift.ClosedAt = &now
+			shift.ClosedByID = &userID
+			if req.Notes != "" {
+				shift.Notes = req.Notes
+			}
+			return tx.Save(&shift).Error
+		})
+
+		if err != nil {
+			return respondError(c, err, "Failed to close shift")
+		}
+
+		return c.JSON(shift)
+	}
+}
+
+// cashDrawer is the key under which all cash payment methods are counted
+const cashDrawer = "cash"
+
+// shiftExpectedAmounts menghitung uang yang seharusnya ada per metode pembayaran:
+// pembayaran di shift ini dikurangi refund yang dibayarkan di shift ini,
+// ditambah modal awal untuk tunai
+func shiftExpectedAmounts(tx *gorm.DB, shift *models.Shift, cashCodes map[string]bool) (map[string]float64, error) {
+	expected := map[string]float64{cashDrawer: shift.OpeningCash}
+
+	var sales []struct {
+		PaymentMethod string
+		Amount        float64
+	}
+	err := tx.Table("transaction_payments tp").
+		Select("tp.payment_method, sum(tp.amount) as amount").
+		Joins("join transactions t on t.id = tp.transaction_id").
+		Where("t.shift_id = ? AND t.status <> ?", shift.ID, models.TransactionStatusVoided).
+		Group("tp.payment_method").
+		Scan(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range sales {
+		expected[drawerKey(row.PaymentMethod, cashCodes)] += row.Amount
+	}
+
+	var refunds []struct {
+		PaymentMethod string
+		Amount        float64
+	}
+	err = tx.Model(&models.Refund{}).
+		Select("payment_method, sum(amount) as amount").
+		Where("shift_id = ?", shift.ID).
+		Group("payment_method").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range refunds {
+		expected[drawerKey(row.PaymentMethod, cashCodes)] -= row.Amount
+	}
+
+	return expected, nil
+}
+
+// drawerKey menyatukan semua metode tunai ke satu laci
+func drawerKey(method string, cashCodes map[string]bool) string {
+	if cashCodes[method] {
+		return cashDrawer
+	}
+	return method
+}
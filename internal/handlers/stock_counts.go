@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StartStockCountRequest defines the body for starting a stock opname.
+// InventoryItemIDs kosong berarti semua bahan baku ikut dihitung.
+type StartStockCountRequest struct {
+	Notes            string `json:"notes"`
+	InventoryItemIDs []uint `json:"inventory_item_ids"`
+}
+
+// StockCountEntryRequest defines the counted quantities entered by staff
+type StockCountEntryRequest struct {
+	Items []struct {
+		InventoryItemID uint    `json:"inventory_item_id"`
+		CountedQuantity float64 `json:"counted_quantity"`
+		Note            string  `json:"note"`
+	} `json:"items"`
+}
+
+// StockCountSummary totals the variance of a stock opname, valued at cost
+type StockCountSummary struct {
+	ItemsTotal        int     `json:"items_total"`
+	ItemsCounted      int     `json:"items_counted"`
+	ItemsWithVariance int     `json:"items_with_variance"`
+	ShrinkageValue    float64 `json:"shrinkage_value"` // nilai stok yang hilang (positif)
+	SurplusValue      float64 `json:"surplus_value"`
+	NetVarianceValue  float64 `json:"net_variance_value"`
+}
+
+// StockCountResponse is a stock opname with its variance summary
+type StockCountResponse struct {
+	models.StockCount
+	Summary StockCountSummary `json:"summary"`
+}
+
+func toStockCountResponse(count models.StockCount) StockCountResponse {
+	summary := StockCountSummary{ItemsTotal: len(count.Items)}
+	for _, item := range count.Items {
+		if item.CountedQuantity == nil {
+			continue
+		}
+		summary.ItemsCounted++
+		if item.Variance == 0 {
+			continue
+		}
+		summary.ItemsWithVariance++
+		if item.VarianceValue < 0 {
+			summary.ShrinkageValue -= item.VarianceValue
+		} else {
+			summary.SurplusValue += item.VarianceValue
+		}
+	}
+	summary.ShrinkageValue = services.RoundMoney(summary.ShrinkageValue)
+	summary.SurplusValue = services.RoundMoney(summary.SurplusValue)
+	summary.NetVarianceValue = services.RoundMoney(summary.SurplusValue - summary.ShrinkageValue)
+	return StockCountResponse{StockCount: count, Summary: summary}
+}
+
+// loadStockCount loads a stock opname with its items in name order
+func loadStockCount(db *gorm.DB, id int) (models.StockCount, error) {
+	var count models.StockCount
+	err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("name, id") }).First(&count, id).Error
+	return count, err
+}
+
+// lockOpenStockCount locks a stock opname that is still in progress
+func lockOpenStockCount(tx *gorm.DB, id int) (models.StockCount, error) {
+	var count models.StockCount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&count, id).Error; err != nil {
+		return count, fiber.NewError(fiber.StatusNotFound, "Stock count not found")
+	}
+	if count.Status != models.StockCountInProgress {
+		return count, fiber.NewError(fiber.StatusConflict, "Stock count has already been "+string(count.Status))
+	}
+	return count, nil
+}
+
+// GetStockCounts handles listing stock opname sessions, newest first.
+// Filter: status, start_date/end_date (tanggal mulai). Opname yang selesai adalah laporannya.
+func GetStockCounts(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		query := db.Preload("Items")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		query = whereDateRange(query, "started_at", startDate, endDate)
+
+		var counts []models.StockCount
+		if err := query.Order("started_at desc, id desc").Find(&counts).Error; err != nil {
+			return respondError(c, err, "Failed to fetch stock counts")
+		}
+
+		// Daftar hanya berisi ringkasan, detail item lewat GET /stock-counts/:id
+		response := make([]StockCountResponse, 0, len(counts))
+		for _, count := range counts {
+			summarized := toStockCountResponse(count)
+			summarized.Items = nil
+			response = append(response, summarized)
+		}
+		return c.JSON(response)
+	}
+}
+
+// GetStockCount handles the variance report of a single stock opname
+func GetStockCount(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock count ID"})
+		}
+		count, err := loadStockCount(db, id)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Stock count not found"})
+		}
+		return c.JSON(toStockCountResponse(count))
+	}
+}
+
+// StartStockCount handles starting a stock opname for all or some inventory items.
+// Hanya boleh ada satu opname yang berjalan pada satu waktu.
+func StartStockCount(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req StartStockCountRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var count models.StockCount
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var open int64
+			if err := tx.Model(&models.StockCount{}).Where("status = ?", models.StockCountInProgress).Count(&open).Error; err != nil {
+				return err
+			}
+			if open > 0 {
+				return fiber.NewError(fiber.StatusConflict, "Another stock count is still in progress")
+			}
+
+			query := tx.Order("name, id")
+			if len(req.InventoryItemIDs) > 0 {
+				query = query.Where("id IN ?", req.InventoryItemIDs)
+			}
+			var items []models.InventoryItem
+			if err := query.Find(&items).Error; err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return fiber.NewError(fiber.StatusBadRequest, "No inventory items to count")
+			}
+			if len(req.InventoryItemIDs) > 0 && len(items) != len(uniqueIDs(req.InventoryItemIDs)) {
+				return fiber.NewError(fiber.StatusNotFound, "Inventory item not found")
+			}
+
+			count = models.StockCount{
+				Status:      models.StockCountInProgress,
+				Notes:       req.Notes,
+				StartedByID: userID,
+				StartedAt:   time.Now(),
+			}
+			for _, item := range items {
+				count.Items = append(count.Items, models.StockCountItem{
+					InventoryItemID: item.ID,
+					Name:            item.Name,
+					Unit:            item.Unit,
+					SystemQuantity:  item.StockLevel,
+					CostPerUnit:     item.CostPerUnit,
+				})
+			}
+			return tx.Create(&count).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to start stock count")
+		}
+		return c.Status(fiber.StatusCreated).JSON(toStockCountResponse(count))
+	}
+}
+
+// uniqueIDs returns ids without duplicates
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var unique []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// RecordStockCountItems handles entering counted quantities. Stok sistem dan harga
+// pokok diambil ulang saat item dihitung; item boleh dihitung ulang selama opname
+// belum disetujui.
+func RecordStockCountItems(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock count ID"})
+		}
+		var req StockCountEntryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if len(req.Items) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Enter at least one counted item"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			count, err := lockOpenStockCount(tx, id)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			for _, entry := range req.Items {
+				if entry.CountedQuantity < 0 {
+					return fiber.NewError(fiber.StatusBadRequest, "counted_quantity cannot be negative")
+				}
+				var line models.StockCountItem
+				if err := tx.Where("stock_count_id = ? AND inventory_item_id = ?", count.ID, entry.InventoryItemID).First(&line).Error; err != nil {
+					return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("Inventory item %d is not part of this stock count", entry.InventoryItemID))
+				}
+				var item models.InventoryItem
+				if err := tx.First(&item, entry.InventoryItemID).Error; err != nil {
+					return services.ErrInventoryItemNotFound
+				}
+
+				counted := entry.CountedQuantity
+				variance := counted - item.StockLevel
+				err := tx.Model(&line).Updates(map[string]interface{}{
+					"system_quantity":  item.StockLevel,
+					"counted_quantity": counted,
+					"variance":         variance,
+					"cost_per_unit":    item.CostPerUnit,
+					"variance_value":   services.RoundMoney(variance * item.CostPerUnit),
+					"note":             entry.Note,
+					"counted_by_id":    userID,
+					"counted_at":       now,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to record counts")
+		}
+
+		count, err := loadStockCount(db, id)
+		if err != nil {
+			return respondError(c, err, "Failed to load stock count")
+		}
+		return c.JSON(toStockCountResponse(count))
+	}
+}
+
+// ApproveStockCount handles approving a stock opname: the variance of every counted
+// item is posted as a stock_count movement. Selisih ditambahkan relatif terhadap
+// stok sekarang, jadi penjualan setelah item dihitung tetap terhitung. Item yang
+// belum dihitung dilewati.
+func ApproveStockCount(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock count ID"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		var stockItems []models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			count, err := lockOpenStockCount(tx, id)
+			if err != nil {
+				return err
+			}
+
+			var lines []models.StockCountItem
+			if err := tx.Where("stock_count_id = ? AND counted_quantity IS NOT NULL AND variance <> 0", count.ID).Find(&lines).Error; err != nil {
+				return err
+			}
+			movement := services.MovementInfo{
+				Type:          models.StockMovementStockCount,
+				ReferenceType: models.ReferenceStockCount,
+				ReferenceID:   &count.ID,
+				UserID:        &userID,
+				Note:          fmt.Sprintf("Stock opname #%d", count.ID),
+			}
+			changes := make([]services.StockChange, 0, len(lines))
+			for _, line := range lines {
+				changes = append(changes, services.StockChange{
+					InventoryItemID: line.InventoryItemID,
+					Delta:           line.Variance,
+					MovementInfo:    movement,
+				})
+			}
+			updated, err := services.NewStockService(tx).Apply(changes)
+			if err != nil {
+				return err
+			}
+			stockItems = updated
+
+			return tx.Model(&count).Updates(map[string]interface{}{
+				"status":       models.StockCountCompleted,
+				"closed_by_id": userID,
+				"closed_at":    time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to approve stock count")
+		}
+		services.Events.PublishStockLevels(stockItems)
+
+		count, err := loadStockCount(db, id)
+		if err != nil {
+			return respondError(c, err, "Failed to load stock count")
+		}
+		return c.JSON(toStockCountResponse(count))
+	}
+}
+
+// CancelStockCount handles discarding a stock opname without changing stock
+func CancelStockCount(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid stock count ID"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			count, err := lockOpenStockCount(tx, id)
+			if err != nil {
+				return err
+			}
+			return tx.Model(&count).Updates(map[string]interface{}{
+				"status":       models.StockCountCancelled,
+				"closed_by_id": userID,
+				"closed_at":    time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to cancel stock count")
+		}
+
+		count, err := loadStockCount(db, id)
+		if err != nil {
+			return respondError(c, err, "Failed to load stock count")
+		}
+		return c.JSON(toStockCountResponse(count))
+	}
+}
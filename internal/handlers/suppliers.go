@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"hayoon-bite-backend/internal/models"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SupplierRequest defines the structure for creating/updating a supplier
+type SupplierRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+	Notes   string `json:"notes"`
+}
+
+// GetSuppliers handles fetching all suppliers
+func GetSuppliers(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var suppliers []models.Supplier
+		if err := db.Order("name").Find(&suppliers).Error; err != nil {
+			log.Printf("Error fetching suppliers: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch suppliers"})
+		}
+		return c.JSON(suppliers)
+	}
+}
+
+// CreateSupplier handles creating a new supplier
+func CreateSupplier(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req SupplierRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if req.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name is required"})
+		}
+
+		var existing models.Supplier
+		if err := db.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Supplier with this name already exists"})
+		}
+
+		supplier := models.Supplier{
+			Name:    req.Name,
+			Phone:   req.Phone,
+			Email:   req.Email,
+			Address: req.Address,
+			Notes:   req.Notes,
+		}
+		if err := db.Create(&supplier).Error; err != nil {
+			log.Printf("Error creating supplier: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create supplier"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(supplier)
+	}
+}
+
+// UpdateSupplier handles updating an existing supplier
+func UpdateSupplier(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		var req SupplierRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var existing models.Supplier
+		if err := db.Where("name = ? AND id != ?", req.Name, id).First(&existing).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another supplier with this name already exists"})
+		}
+
+		var supplier models.Supplier
+		if err := db.First(&supplier, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Supplier not found"})
+		}
+
+		supplier.Name = req.Name
+		supplier.Phone = req.Phone
+		supplier.Email = req.Email
+		supplier.Address = req.Address
+		supplier.Notes = req.Notes
+		if err := db.Save(&supplier).Error; err != nil {
+			log.Printf("Error updating supplier: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update supplier"})
+		}
+
+		return c.JSON(supplier)
+	}
+}
+
+// DeleteSupplier handles deleting a supplier that has no purchase orders
+func DeleteSupplier(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid supplier ID"})
+		}
+
+		var poCount int64
+		db.Model(&models.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&poCount)
+		if poCount > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Cannot delete supplier, it has purchase orders."})
+		}
+
+		result := db.Delete(&models.Supplier{}, id)
+		if result.Error != nil {
+			log.Printf("Error deleting supplier: %v", result.Error)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete supplier"})
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Supplier not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Supplier deleted successfully"})
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSyncBatchSize = 100
+	// maxClockSkew memberi toleransi jam tablet kasir yang sedikit lebih cepat dari server
+	maxClockSkew = 5 * time.Minute
+)
+
+// Result status of one transaction in an offline sync batch
+const (
+	syncStatusAccepted  = "accepted"
+	syncStatusDuplicate = "duplicate"
+	syncStatusRejected  = "rejected"
+)
+
+// SyncTransactionRequest is a sale recorded by the POS while it was offline.
+// ClientUUID dibuat oleh POS dan dipakai sebagai idempotency key, TransactionTime
+// adalah waktu penjualan sebenarnya di sisi klien.
+type SyncTransactionRequest struct {
+	ClientUUID      string    `json:"client_uuid"`
+	TransactionTime time.Time `json:"transaction_time"`
+	TransactionRequest
+}
+
+// SyncRequest defines the body for uploading queued offline sales
+type SyncRequest struct {
+	Transactions []SyncTransactionRequest `json:"transactions"`
+}
+
+// SyncResult is the outcome for one transaction of the batch
+type SyncResult struct {
+	ClientUUID    string              `json:"client_uuid"`
+	Status        string              `json:"status"`
+	TransactionID uint                `json:"transaction_id,omitempty"`
+	Error         string              `json:"error,omitempty"`
+	Shortages     []services.Shortage `json:"shortages,omitempty"` // alasan penolakan jika stok kurang
+	Warnings      []services.Shortage `json:"warnings,omitempty"`
+}
+
+// SyncTransactions handles uploading a batch of sales queued by the POS while offline.
+//
+// Transaksi diproses berurutan, masing-masing dalam DB transaction sendiri, dengan
+// logika checkout yang sama seperti CreateTransaction (promo dihitung pada waktu
+// penjualan). Penjualan dicatat di shift kasir yang sedang buka saat sync, karena
+// uang tunainya sudah ada di laci shift tersebut. Transaksi yang sudah pernah
+// dikirim dilaporkan sebagai duplicate, bukan dicatat ulang. Pesanan offline
+// dianggap sudah diambil sehingga tidak muncul di layar dapur.
+func SyncTransactions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req SyncRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if len(req.Transactions) == 0 || len(req.Transactions) > maxSyncBatchSize {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("A batch must contain between 1 and %d transactions", maxSyncBatchSize)})
+		}
+
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		results := make([]SyncResult, 0, len(req.Transactions))
+		counts := map[string]int{syncStatusAccepted: 0, syncStatusDuplicate: 0, syncStatusRejected: 0}
+		for _, entry := range req.Transactions {
+			result := syncTransaction(db, entry, userID)
+			counts[result.Status]++
+			results = append(results, result)
+		}
+
+		return c.JSON(fiber.Map{
+			"accepted":  counts[syncStatusAccepted],
+			"duplicate": counts[syncStatusDuplicate],
+			"rejected":  counts[syncStatusRejected],
+			"results":   results,
+		})
+	}
+}
+
+// syncTransaction records one offline sale and reports what happened to it
+func syncTransaction(db *gorm.DB, entry SyncTransactionRequest, userID uint) SyncResult {
+	result := SyncResult{ClientUUID: entry.ClientUUID}
+	reject := func(message string) SyncResult {
+		result.Status = syncStatusRejected
+		result.Error = message
+		return result
+	}
+
+	clientUUID, err := uuid.Parse(entry.ClientUUID)
+	if err != nil {
+		return reject("Invalid client_uuid")
+	}
+	key := clientUUID.String()
+
+	if entry.TransactionTime.IsZero() {
+		return reject("transaction_time is required")
+	}
+	if entry.TransactionTime.After(time.Now().Add(maxClockSkew)) {
+		return reject("transaction_time is in the future")
+	}
+
+	duplicate := func() (SyncResult, bool) {
+		existing, found, err := transactionByIdempotencyKey(db, key)
+		if err != nil || !found {
+			return result, false
+		}
+		if existing.UserID == nil || *existing.UserID != userID {
+			return reject("client_uuid has already been used"), true
+		}
+		result.Status = syncStatusDuplicate
+		result.TransactionID = existing.ID
+		return result, true
+	}
+	if r, found := duplicate(); found {
+		return r
+	}
+
+	var recorded checkoutResult
+	err = db.Transaction(func(tx *gorm.DB) error {
+		shift, err := findOpenShift(tx, userID)
+		if err != nil {
+			return err
+		}
+		recorded, err = checkout(tx, entry.TransactionRequest, userID, shift, entry.TransactionTime, key)
+		if err != nil {
+			return err
+		}
+
+		// Pesanan offline sudah diserahkan saat dijual, jadi tidak masuk antrean dapur
+		return tx.Model(&models.Transaction{}).Where("id = ?", recorded.Transaction.ID).Updates(map[string]interface{}{
+			"order_status": models.OrderStatusPickedUp,
+			"picked_up_at": entry.TransactionTime,
+		}).Error
+	})
+	if err != nil {
+		// Batch yang sama terkirim dua kali secara bersamaan
+		if r, found := duplicate(); found {
+			return r
+		}
+		_, body := errorResponse(err, "Failed to record transaction")
+		result.Shortages, _ = body["shortages"].([]services.Shortage)
+		message, _ := body["error"].(string)
+		return reject(message)
+	}
+
+	if saved, err := loadTransaction(db, recorded.Transaction.ID); err == nil {
+		publishSaleEvents(saved, recorded.StockItems)
+	}
+
+	result.Status = syncStatusAccepted
+	result.TransactionID = recorded.Transaction.ID
+	result.Warnings = recorded.Shortages
+	return result
+}
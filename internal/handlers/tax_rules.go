@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TaxRuleRequest defines the structure for creating/updating a tax or service charge rule
+type TaxRuleRequest struct {
+	Name         string            `json:"name" validate:"required"`
+	Kind         models.ChargeKind `json:"kind"`
+	Rate         float64           `json:"rate"` // persen, mis. 10 untuk PB1 10%
+	Inclusive    bool              `json:"inclusive"`
+	IsActive     *bool             `json:"is_active"` // default true
+	DisplayOrder int               `json:"display_order"`
+}
+
+func (req TaxRuleRequest) toTaxRule() (models.TaxRule, error) {
+	if req.Name == "" {
+		return models.TaxRule{}, fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+	if req.Kind != models.ChargeKindTax && req.Kind != models.ChargeKindServiceCharge {
+		return models.TaxRule{}, fiber.NewError(fiber.StatusBadRequest, "Invalid kind, use tax or service_charge")
+	}
+	if req.Rate < 0 || req.Rate > 100 {
+		return models.TaxRule{}, fiber.NewError(fiber.StatusBadRequest, "Rate must be between 0 and 100")
+	}
+	return models.TaxRule{
+		Name:         req.Name,
+		Kind:         req.Kind,
+		Rate:         req.Rate,
+		Inclusive:    req.Inclusive,
+		IsActive:     req.IsActive == nil || *req.IsActive,
+		DisplayOrder: req.DisplayOrder,
+	}, nil
+}
+
+// GetTaxRules handles fetching all tax and service charge rules
+func GetTaxRules(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var rules []models.TaxRule
+		if err := db.Order("display_order, id").Find(&rules).Error; err != nil {
+			return respondError(c, err, "Failed to fetch tax rules")
+		}
+		return c.JSON(rules)
+	}
+}
+
+// CreateTaxRule handles creating a new tax or service charge rule
+func CreateTaxRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req TaxRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		rule, err := req.toTaxRule()
+		if err != nil {
+			return respondError(c, err, "Invalid tax rule")
+		}
+		// Select("*") supaya is_active=false tetap tersimpan (bukan diganti default DB)
+		if err := db.Select("*").Omit("id", "created_at", "updated_at").Create(&rule).Error; err != nil {
+			return respondError(c, err, "Failed to create tax rule")
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(rule)
+	}
+}
+
+// UpdateTaxRule handles updating a tax or service charge rule.
+// Transaksi lama tidak berubah karena tarifnya disimpan sebagai snapshot.
+func UpdateTaxRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tax rule ID"})
+		}
+
+		var req TaxRuleRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		var existing models.TaxRule
+		if err := db.First(&existing, id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tax rule not found"})
+		}
+
+		rule, err := req.toTaxRule()
+		if err != nil {
+			return respondError(c, err, "Invalid tax rule")
+		}
+		rule.ID = existing.ID
+		rule.CreatedAt = existing.CreatedAt
+		rule.UpdatedAt = time.Now()
+		if err := db.Save(&rule).Error; err != nil {
+			return respondError(c, err, "Failed to update tax rule")
+		}
+
+		return c.JSON(rule)
+	}
+}
+
+// DeleteTaxRule handles deleting a tax or service charge rule
+func DeleteTaxRule(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tax rule ID"})
+		}
+
+		result := db.Delete(&models.TaxRule{}, id)
+		if result.Error != nil {
+			return respondError(c, result.Error, "Failed to delete tax rule")
+		}
+		if result.RowsAffected == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tax rule not found"})
+		}
+
+		return c.JSON(fiber.Map{"message": "Tax rule deleted successfully"})
+	}
+}
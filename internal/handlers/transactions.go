@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loadTransaction loads a transaction with its items, discounts, charges and payments
+func loadTransaction(db *gorm.DB, id uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Product").
+		Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Discounts", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Charges", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("User").
+		Preload("Customer").
+		First(&transaction, id).Error
+	return transaction, err
+}
+
+const (
+	// idempotencyKeyHeader lets a POS client retry a checkout safely
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 100
+)
+
+// normalizeIdempotencyKey writes a key that is a UUID in canonical lowercase form,
+// sama seperti client_uuid di sync offline, supaya penjualan yang dikirim ulang lewat
+// /sync dengan huruf berbeda tetap dikenali. Key lain dipakai apa adanya.
+func normalizeIdempotencyKey(key string) string {
+	key = strings.TrimSpace(key)
+	if id, err := uuid.Parse(key); err == nil {
+		return id.String()
+	}
+	return key
+}
+
+// transactionByIdempotencyKey finds a transaction recorded earlier with the same key
+func transactionByIdempotencyKey(db *gorm.DB, key string) (models.Transaction, bool, error) {
+	var transaction models.Transaction
+	err := db.Where("idempotency_key = ?", key).First(&transaction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return transaction, false, nil
+	}
+	return transaction, err == nil, err
+}
+
+// replayTransaction responds to a retried checkout with the transaction that was
+// already recorded, in the same shape as the original response
+func replayTransaction(c *fiber.Ctx, db *gorm.DB, existing models.Transaction, userID uint) error {
+	// Key milik kasir lain berarti bentrok, bukan request ulang
+	if existing.UserID == nil || *existing.UserID != userID {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Idempotency key has already been used"})
+	}
+
+	saved, err := loadTransaction(db, existing.ID)
+	if err != nil {
+		return respondError(c, err, "Failed to load transaction")
+	}
+	c.Set("Idempotent-Replayed", "true")
+	return c.JSON(fiber.Map{
+		"message":     "Transaction successful",
+		"transaction": saved,
+	})
+}
+
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+// GetTransactions handles listing transactions, newest first.
+//
+// Filter: start_date/end_date (YYYY-MM-DD), payment_method (termasuk bagian dari
+// pembayaran split), user_id (kasir), customer_id, product_id, min_amount/max_amount
+// (total), status, dan search (nomor transaksi atau nama produk).
+// Paginasi memakai cursor: kirim next_cursor dari respons sebelumnya sebagai ?cursor=.
+func GetTransactions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		limit := c.QueryInt("limit", defaultTransactionPageSize)
+		if limit < 1 || limit > maxTransactionPageSize {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("limit must be between 1 and %d", maxTransactionPageSize)})
+		}
+
+		query := whereDateRange(db.Model(&models.Transaction{}), "transaction_time", startDate, endDate)
+		if method := normalizePaymentCode(c.Query("payment_method")); method != "" {
+			query = query.Where("exists (select 1 from transaction_payments tp where tp.transaction_id = transactions.id and tp.payment_method = ?)", method)
+		}
+		if userID := c.QueryInt("user_id"); userID > 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		if customerID := c.QueryInt("customer_id"); customerID > 0 {
+			query = query.Where("customer_id = ?", customerID)
+		}
+		if productID := c.QueryInt("product_id"); productID > 0 {
+			query = query.Where("exists (select 1 from transaction_items ti where ti.transaction_id = transactions.id and ti.product_id = ?)", productID)
+		}
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		for param, condition := range map[string]string{"min_amount": "total_amount >= ?", "max_amount": "total_amount <= ?"} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + param})
+			}
+			query = query.Where(condition, amount)
+		}
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			nameMatch := "exists (select 1 from transaction_items ti where ti.transaction_id = transactions.id and ti.product_name ilike ?)"
+			pattern := "%" + search + "%"
+			if id, err := strconv.ParseUint(strings.TrimPrefix(search, "#"), 10, 32); err == nil {
+				query = query.Where("(transactions.id = ? or "+nameMatch+")", id, pattern)
+			} else {
+				query = query.Where(nameMatch, pattern)
+			}
+		}
+		if cursor := c.Query("cursor"); cursor != "" {
+			cursorTime, cursorID, err := decodeTransactionCursor(cursor)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+			}
+			query = query.Where("(transaction_time, transactions.id) < (?, ?)", cursorTime, cursorID)
+		}
+
+		// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya
+		var transactions []models.Transaction
+		err = query.
+			Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Preload("Items.Product").
+			Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			Preload("User").
+			Preload("Customer").
+			Order("transaction_time desc, transactions.id desc").
+			Limit(limit + 1).
+			Find(&transactions).Error
+		if err != nil {
+			return respondError(c, err, "Failed to fetch transactions")
+		}
+
+		var nextCursor *string
+		if len(transactions) > limit {
+			transactions = transactions[:limit]
+			cursor := encodeTransactionCursor(transactions[limit-1])
+			nextCursor = &cursor
+		}
+
+		return c.JSON(fiber.Map{
+			"transactions": transactions,
+			"next_cursor":  nextCursor,
+		})
+	}
+}
+
+// GetTransaction handles fetching a single transaction with its lines and product details
+func GetTransaction(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+		}
+
+		transaction, err := loadTransaction(db, uint(id))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Transaction not found"})
+		}
+		return c.JSON(transaction)
+	}
+}
+
+// encodeTransactionCursor menyimpan posisi baris terakhir (waktu transaksi, id) sebagai token opaque
+func encodeTransactionCursor(transaction models.Transaction) string {
+	raw := fmt.Sprintf("%d:%d", transaction.TransactionTime.UnixMicro(), transaction.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTransactionCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor")
+	}
+	unixMicro, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	transactionID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.UnixMicro(unixMicro), uint(transactionID), nil
+}
+
+// GetTransactionReceipt handles rendering the receipt of a transaction.
+// ?format=text (default), escpos atau pdf; ?width=58 (default) atau 80 mm.
+func GetTransactionReceipt(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid transaction ID"})
+		}
+
+		paper := services.ReceiptPaper(c.QueryInt("width", int(services.ReceiptPaper58mm)))
+		if !paper.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid width, use 58 or 80"})
+		}
+		format := c.Query("format", "text")
+		if format != "text" && format != "escpos" && format != "pdf" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid format, use text, escpos or pdf"})
+		}
+
+		transaction, err := loadTransaction(db, uint(id))
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Transaction not found"})
+		}
+		receipt, err := services.NewReceipt(db, transaction)
+		if err != nil {
+			return respondError(c, err, "Failed to build receipt")
+		}
+
+		switch format {
+		case "escpos":
+			c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+			c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="receipt-%d.bin"`, transaction.ID))
+			return c.Send(receipt.ESCPOS(paper))
+		case "pdf":
+			c.Set(fiber.HeaderContentType, "application/pdf")
+			c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="receipt-%d.pdf"`, transaction.ID))
+			return c.Send(receipt.PDF(paper))
+		default:
+			c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+			return c.Send(receipt.Text(paper))
+		}
+	}
+}
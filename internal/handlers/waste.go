@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/middleware"
+	"hayoon-bite-backend/internal/models"
+	"hayoon-bite-backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// WasteRequest defines the body for recording waste. Isi salah satu:
+// inventory_item_id (bahan baku) atau product_id (produk jadi, jumlah harus bulat).
+type WasteRequest struct {
+	InventoryItemID *uint              `json:"inventory_item_id"`
+	ProductID       *uint              `json:"product_id"`
+	Quantity        float64            `json:"quantity"`
+	Reason          models.WasteReason `json:"reason"`
+	Note            string             `json:"note"`
+}
+
+// CreateWasteRecord handles recording stock that was thrown away and deducts it
+// from stock. Produk jadi memotong bahan sesuai resepnya.
+func CreateWasteRecord(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req WasteRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		if (req.InventoryItemID == nil) == (req.ProductID == nil) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Provide either inventory_item_id or product_id"})
+		}
+		if req.Quantity <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Quantity must be greater than zero"})
+		}
+		if req.ProductID != nil && req.Quantity != math.Trunc(req.Quantity) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Quantity of a product must be a whole number"})
+		}
+		if !req.Reason.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid reason, use expired, dropped, burnt or staff_meal"})
+		}
+		userID, _, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+
+		record := models.WasteRecord{
+			InventoryItemID: req.InventoryItemID,
+			ProductID:       req.ProductID,
+			Quantity:        req.Quantity,
+			Reason:          req.Reason,
+			Note:            strings.TrimSpace(req.Note),
+			UserID:          userID,
+			RecordedAt:      time.Now(),
+		}
+		var stockItems []models.InventoryItem
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// Nilai dihitung dari harga pokok bahan saat ini, sebelum stok dipotong
+			if req.ProductID != nil {
+				var product models.Product
+				if err := tx.First(&product, *req.ProductID).Error; err != nil {
+					return fiber.NewError(fiber.StatusNotFound, "Product not found")
+				}
+				unitCost, err := recipeUnitCost(tx, product.ID)
+				if err != nil {
+					return err
+				}
+				record.Name = product.Name
+				record.Unit = "pcs"
+				record.CostAmount = services.RoundMoney(unitCost * req.Quantity)
+			} else {
+				var item models.InventoryItem
+				if err := tx.First(&item, *req.InventoryItemID).Error; err != nil {
+					return services.ErrInventoryItemNotFound
+				}
+				record.Name = item.Name
+				record.Unit = item.Unit
+				record.CostAmount = services.RoundMoney(item.CostPerUnit * req.Quantity)
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+
+			stock := services.NewStockService(tx)
+			movement := services.MovementInfo{
+				Type:          models.StockMovementWaste,
+				ReferenceType: models.ReferenceWaste,
+				ReferenceID:   &record.ID,
+				UserID:        &userID,
+				Note:          string(req.Reason),
+			}
+			changes := []services.StockChange{}
+			if req.ProductID != nil {
+				changes, err = stock.RecipeChanges(*req.ProductID, int(req.Quantity), -1, movement)
+				if err != nil {
+					return err
+				}
+			} else {
+				changes = append(changes, services.StockChange{
+					InventoryItemID: *req.InventoryItemID,
+					Delta:           -req.Quantity,
+					MovementInfo:    movement,
+				})
+			}
+			stockItems, err = stock.Apply(changes)
+			return err
+		})
+		if err != nil {
+			return respondError(c, err, "Failed to record waste")
+		}
+		services.Events.PublishStockLevels(stockItems)
+
+		return c.Status(fiber.StatusCreated).JSON(record)
+	}
+}
+
+// GetWasteRecords handles listing waste records, newest first.
+// Filter: start_date/end_date, reason, inventory_item_id, product_id.
+func GetWasteRecords(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		query := whereDateRange(db.Model(&models.WasteRecord{}), "recorded_at", startDate, endDate)
+		if reason := models.WasteReason(c.Query("reason")); reason != "" {
+			if !reason.Valid() {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid reason, use expired, dropped, burnt or staff_meal"})
+			}
+			query = query.Where("reason = ?", reason)
+		}
+		if itemID := c.QueryInt("inventory_item_id"); itemID > 0 {
+			query = query.Where("inventory_item_id = ?", itemID)
+		}
+		if productID := c.QueryInt("product_id"); productID > 0 {
+			query = query.Where("product_id = ?", productID)
+		}
+
+		records := []models.WasteRecord{}
+		if err := query.Order("recorded_at desc, id desc").Find(&records).Error; err != nil {
+			return respondError(c, err, "Failed to fetch waste records")
+		}
+		return c.JSON(records)
+	}
+}
+
+// WasteReasonRow is the total waste of one reason code
+type WasteReasonRow struct {
+	Reason  models.WasteReason `json:"reason"`
+	Records int                `json:"records"`
+	Cost    float64            `json:"cost"`
+}
+
+// WastePeriod is one bucket (day, week or month) of the waste report
+type WastePeriod struct {
+	Period   string                         `json:"period"` // tanggal awal periode
+	Cost     float64                        `json:"cost"`
+	ByReason map[models.WasteReason]float64 `json:"by_reason"`
+}
+
+// WasteItemRow is the total waste of one inventory item or product
+type WasteItemRow struct {
+	InventoryItemID *uint   `json:"inventory_item_id,omitempty"`
+	ProductID       *uint   `json:"product_id,omitempty"`
+	Name            string  `json:"name"`
+	Unit            string  `json:"unit"`
+	Quantity        float64 `json:"quantity"`
+	Records         int     `json:"records"`
+	Cost            float64 `json:"cost"`
+}
+
+// WasteReportResponse defines the waste and spoilage report
+type WasteReportResponse struct {
+	GroupBy   string           `json:"group_by"`
+	TotalCost float64          `json:"total_cost"`
+	Records   int              `json:"records"`
+	ByReason  []WasteReasonRow `json:"by_reason"`
+	Periods   []WastePeriod    `json:"periods"`
+	Items     []WasteItemRow   `json:"items"` // urut dari nilai terbesar
+}
+
+// GetWasteReport reports the cost of waste by reason, by day/week/month
+// (default week) and by item for a date range. Nilai memakai harga pokok saat
+// waste dicatat.
+func GetWasteReport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		startDate, endDate, err := parseDateRange(c)
+		if err != nil {
+			return respondError(c, err, "Invalid date range")
+		}
+
+		groupBy := c.Query("group_by", "week")
+		field, ok := profitLossGroupings[groupBy]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid group_by, use day, week or month"})
+		}
+
+		var rows []struct {
+			Period  time.Time
+			Reason  models.WasteReason
+			Records int
+			Cost    float64
+		}
+		query := db.Model(&models.WasteRecord{}).
+			Select("date_trunc(?, recorded_at) as period, reason, count(*) as records, sum(cost_amount) as cost", field).
+			Group("period, reason").
+			Order("period")
+		query = whereDateRange(query, "recorded_at", startDate, endDate)
+		if err := query.Scan(&rows).Error; err != nil {
+			return respondError(c, err, "Failed to generate waste report")
+		}
+
+		response := WasteReportResponse{GroupBy: groupBy, ByReason: []WasteReasonRow{}, Periods: []WastePeriod{}, Items: []WasteItemRow{}}
+		byReason := make(map[models.WasteReason]*WasteReasonRow)
+		periods := make(map[string]*WastePeriod)
+		for _, r := range rows {
+			key := r.Period.Format("2006-01-02")
+			period, ok := periods[key]
+			if !ok {
+				period = &WastePeriod{Period: key, ByReason: make(map[models.WasteReason]float64)}
+				periods[key] = period
+			}
+			period.Cost += r.Cost
+			period.ByReason[r.Reason] += r.Cost
+
+			reason, ok := byReason[r.Reason]
+			if !ok {
+				reason = &WasteReasonRow{Reason: r.Reason}
+				byReason[r.Reason] = reason
+			}
+			reason.Records += r.Records
+			reason.Cost += r.Cost
+
+			response.Records += r.Records
+			response.TotalCost += r.Cost
+		}
+		for _, p := range periods {
+			response.Periods = append(response.Periods, *p)
+		}
+		sort.Slice(response.Periods, func(i, j int) bool {
+			return response.Periods[i].Period < response.Periods[j].Period
+		})
+		for _, r := range byReason {
+			response.ByReason = append(response.ByReason, *r)
+		}
+		sort.Slice(response.ByReason, func(i, j int) bool {
+			return response.ByReason[i].Cost > response.ByReason[j].Cost
+		})
+
+		query = db.Model(&models.WasteRecord{}).
+			Select("inventory_item_id, product_id, max(name) as name, max(unit) as unit, sum(quantity) as quantity, count(*) as records, sum(cost_amount) as cost").
+			Group("inventory_item_id, product_id").
+			Order("cost desc")
+		query = whereDateRange(query, "recorded_at", startDate, endDate)
+		if err := query.Scan(&response.Items).Error; err != nil {
+			return respondError(c, err, "Failed to generate waste report")
+		}
+
+		response.TotalCost = services.RoundMoney(response.TotalCost)
+		return c.JSON(response)
+	}
+}
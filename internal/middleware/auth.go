@@ -60,6 +60,19 @@ func GenerateJWT(userID uint, role models.Role) (string, error) {
 	return token.SignedString(secret)
 }
 
+// TokenFromQuery lets clients that cannot set headers (mis. EventSource di browser)
+// send the JWT as ?token=. Pasang sebelum JWTProtected.
+func TokenFromQuery() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request().Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
+
 // JWTProtected protects routes with JWT authentication
 func JWTProtected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -6,13 +6,86 @@ import "time"
 // INVENTORY & PRODUCT
 // ==========================================
 
+// StockPolicy menentukan apa yang terjadi jika penjualan membuat stok minus
+type StockPolicy string
+
+const (
+	StockPolicyBlock StockPolicy = "block" // tolak penjualan
+	StockPolicyWarn  StockPolicy = "warn"  // izinkan, tapi beri peringatan di response
+	StockPolicyAllow StockPolicy = "allow" // izinkan tanpa peringatan
+)
+
+// Valid reports whether p is one of the known policies
+func (p StockPolicy) Valid() bool {
+	switch p {
+	case StockPolicyBlock, StockPolicyWarn, StockPolicyAllow:
+		return true
+	}
+	return false
+}
+
 type InventoryItem struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	Name       string    `gorm:"not null;unique" json:"name"`
-	StockLevel float64   `gorm:"not null;default:0.00" json:"stock_level"`
-	Unit       string    `gorm:"not null" json:"unit"`
-	CreatedAt  time.Time `gorm:"default:now()" json:"created_at"`
-	UpdatedAt  time.Time `gorm:"default:now()" json:"updated_at"`
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	Name        string  `gorm:"not null;unique" json:"name"`
+	StockLevel  float64 `gorm:"not null;default:0.00" json:"stock_level"`
+	Unit        string  `gorm:"not null" json:"unit"`
+	CostPerUnit float64 `gorm:"not null;default:0" json:"cost_per_unit"`
+
+	// Kosong berarti mengikuti pengaturan global (setting "stock_policy")
+	StockPolicy StockPolicy `gorm:"type:varchar(10);not null;default:''" json:"stock_policy"`
+
+	// Batas stok menipis untuk notifikasi; 0 berarti tanpa notifikasi
+	LowStockThreshold float64 `gorm:"not null;default:0" json:"low_stock_threshold"`
+
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+type StockMovementType string
+
+const (
+	StockMovementStockIn    StockMovementType = "stock_in"
+	StockMovementSale       StockMovementType = "sale"
+	StockMovementVoid       StockMovementType = "void"
+	StockMovementRefund     StockMovementType = "refund"
+	StockMovementAdjustment StockMovementType = "adjustment"
+	StockMovementPurchase   StockMovementType = "purchase"
+	StockMovementStockCount StockMovementType = "stock_count" // selisih hasil stock opname
+	StockMovementWaste      StockMovementType = "waste"
+)
+
+// Reference types untuk StockMovement
+const (
+	ReferenceTransaction   = "transaction"
+	ReferencePurchaseOrder = "purchase_order"
+	ReferenceStockCount    = "stock_count"
+	ReferenceWaste         = "waste"
+)
+
+// StockMovement is an append-only ledger entry for every change to InventoryItem.StockLevel
+type StockMovement struct {
+	ID              uint              `gorm:"primaryKey" json:"id"`
+	InventoryItemID uint              `gorm:"not null;index" json:"inventory_item_id"`
+	InventoryItem   InventoryItem     `gorm:"foreignKey:InventoryItemID" json:"-"`
+	Type            StockMovementType `gorm:"type:varchar(30);not null" json:"type"`
+	Quantity        float64           `gorm:"not null" json:"quantity"` // positif = masuk, negatif = keluar
+	BalanceAfter    float64           `gorm:"not null" json:"balance_after"`
+	ReferenceType   string            `gorm:"type:varchar(30)" json:"reference_type,omitempty"`
+	ReferenceID     *uint             `json:"reference_id,omitempty"`
+	UserID          *uint             `json:"user_id,omitempty"`
+	User            *User             `gorm:"foreignKey:UserID" json:"-"`
+	Note            string            `json:"note,omitempty"`
+	CreatedAt       time.Time         `gorm:"default:now();index" json:"created_at"`
+}
+
+// ProductCategory groups products on the POS and the public menu, e.g. "Toast Manis"
+type ProductCategory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"not null;unique" json:"name"`
+	DisplayOrder int       `gorm:"not null;default:0" json:"display_order"`
+	IsActive     bool      `gorm:"not null;default:true" json:"is_active"` // false = disembunyikan dari menu
+	CreatedAt    time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"default:now()" json:"updated_at"`
 }
 
 type Product struct {
@@ -20,6 +93,9 @@ type Product struct {
 	Name  string  `gorm:"not null;unique" json:"name"`
 	Price float64 `gorm:"not null" json:"price"`
 
+	CategoryID *uint            `gorm:"index" json:"category_id"`
+	Category   *ProductCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+
 	// UPDATE: Menambahkan ImagePath untuk menyimpan link gambar
 	ImagePath string `gorm:"default:null" json:"image_path"`
 
@@ -36,15 +112,124 @@ type RecipeItem struct {
 	QuantityUsed    float64       `gorm:"not null" json:"quantity_used"`
 }
 
+// ModifierGroup is a set of options for a product, e.g. "Topping" on a toast.
+// MaxSelect 0 berarti boleh pilih sebanyak apa pun.
+type ModifierGroup struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	ProductID    uint             `gorm:"not null;index" json:"product_id"`
+	Name         string           `gorm:"not null" json:"name"`
+	MinSelect    int              `gorm:"not null;default:0" json:"min_select"`
+	MaxSelect    int              `gorm:"not null;default:0" json:"max_select"`
+	DisplayOrder int              `gorm:"not null;default:0" json:"display_order"`
+	Options      []ModifierOption `gorm:"foreignKey:ModifierGroupID" json:"options"`
+}
+
+// ModifierOption is one choice in a modifier group, e.g. "Extra Keju"
+type ModifierOption struct {
+	ID              uint                 `gorm:"primaryKey" json:"id"`
+	ModifierGroupID uint                 `gorm:"not null;index" json:"modifier_group_id"`
+	Name            string               `gorm:"not null" json:"name"`
+	PriceDelta      float64              `gorm:"not null;default:0" json:"price_delta"`
+	DisplayOrder    int                  `gorm:"not null;default:0" json:"display_order"`
+	Recipe          []ModifierRecipeItem `gorm:"foreignKey:ModifierOptionID" json:"recipe"`
+}
+
+// ModifierRecipeItem is the extra ingredient usage of a modifier option, per unit sold
+type ModifierRecipeItem struct {
+	ID               uint          `gorm:"primaryKey" json:"id"`
+	ModifierOptionID uint          `gorm:"not null;index" json:"modifier_option_id"`
+	InventoryItemID  uint          `gorm:"not null" json:"inventory_item_id"`
+	InventoryItem    InventoryItem `gorm:"foreignKey:InventoryItemID" json:"inventory_item,omitempty"`
+	QuantityUsed     float64       `gorm:"not null" json:"quantity_used"`
+}
+
 // ==========================================
 // POS & TRANSACTIONS
 // ==========================================
 
+type TransactionStatus string
+
+const (
+	TransactionStatusCompleted         TransactionStatus = "completed"
+	TransactionStatusVoided            TransactionStatus = "voided"
+	TransactionStatusPartiallyRefunded TransactionStatus = "partially_refunded"
+	TransactionStatusRefunded          TransactionStatus = "refunded"
+)
+
+// OrderStatus tracks the preparation of a sold order in the kitchen
+type OrderStatus string
+
+const (
+	OrderStatusQueued    OrderStatus = "queued"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusReady     OrderStatus = "ready"
+	OrderStatusPickedUp  OrderStatus = "picked_up"
+)
+
+// orderStatusSteps urutan status pesanan, hanya boleh maju
+var orderStatusSteps = map[OrderStatus]int{
+	OrderStatusQueued:    1,
+	OrderStatusPreparing: 2,
+	OrderStatusReady:     3,
+	OrderStatusPickedUp:  4,
+}
+
+// Valid reports whether s is a known order status
+func (s OrderStatus) Valid() bool {
+	_, ok := orderStatusSteps[s]
+	return ok
+}
+
+// CanMoveTo reports whether an order may go from s to next (hanya maju, boleh melompati langkah)
+func (s OrderStatus) CanMoveTo(next OrderStatus) bool {
+	return next.Valid() && orderStatusSteps[next] > orderStatusSteps[s]
+}
+
 type Transaction struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	TotalAmount     float64   `gorm:"not null" json:"total_amount"`
-	PaymentMethod   string    `gorm:"not null" json:"payment_method"`
-	TransactionTime time.Time `gorm:"default:now()" json:"transaction_time"`
+	ID              uint                 `gorm:"primaryKey" json:"id"`
+	TotalAmount     float64              `gorm:"not null" json:"total_amount"`
+	PaymentMethod   string               `gorm:"not null" json:"payment_method"` // kode metode, atau "split" jika lebih dari satu
+	Items           []TransactionItem    `gorm:"foreignKey:TransactionID" json:"items,omitempty"`
+	Payments        []TransactionPayment `gorm:"foreignKey:TransactionID" json:"payments,omitempty"`
+	TransactionTime time.Time            `gorm:"default:now()" json:"transaction_time"`
+	Status          TransactionStatus    `gorm:"type:varchar(20);not null;default:'completed'" json:"status"`
+	RefundedAmount  float64              `gorm:"not null;default:0" json:"refunded_amount"`
+
+	// TotalAmount = Subtotal - DiscountAmount + biaya layanan & pajak yang eksklusif
+	Subtotal            float64               `gorm:"not null;default:0" json:"subtotal"`
+	DiscountAmount      float64               `gorm:"not null;default:0" json:"discount_amount"`
+	ServiceChargeAmount float64               `gorm:"not null;default:0" json:"service_charge_amount"`
+	TaxAmount           float64               `gorm:"not null;default:0" json:"tax_amount"`
+	Discounts           []TransactionDiscount `gorm:"foreignKey:TransactionID" json:"discounts,omitempty"`
+	Charges             []TransactionCharge   `gorm:"foreignKey:TransactionID" json:"charges,omitempty"`
+
+	// Status pesanan di dapur; QueueNumber adalah nomor antrean harian untuk pelanggan
+	OrderStatus OrderStatus `gorm:"type:varchar(20);not null;default:'queued'" json:"order_status"`
+	QueueNumber int         `gorm:"not null;default:0" json:"queue_number"`
+	PreparingAt *time.Time  `json:"preparing_at,omitempty"`
+	ReadyAt     *time.Time  `json:"ready_at,omitempty"`
+	PickedUpAt  *time.Time  `json:"picked_up_at,omitempty"`
+
+	// Kasir dan shift tempat transaksi dicatat (kosong untuk data lama)
+	UserID  *uint  `gorm:"index" json:"user_id"`
+	User    *User  `gorm:"foreignKey:UserID" json:"cashier,omitempty"`
+	ShiftID *uint  `gorm:"index" json:"shift_id"`
+	Shift   *Shift `gorm:"foreignKey:ShiftID" json:"-"`
+
+	// Pelanggan (opsional) beserta poin loyalitas yang didapat dan ditukar di transaksi ini
+	CustomerID     *uint     `gorm:"index" json:"customer_id"`
+	Customer       *Customer `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	PointsEarned   int       `gorm:"not null;default:0" json:"points_earned"`
+	PointsRedeemed int       `gorm:"not null;default:0" json:"points_redeemed"`
+
+	// Key dari klien (header Idempotency-Key atau UUID transaksi) supaya request ulang tidak tercatat dua kali
+	IdempotencyKey *string `gorm:"size:100;uniqueIndex" json:"idempotency_key,omitempty"`
+
+	// Void hanya untuk membatalkan transaksi yang salah input di hari yang sama
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+	VoidedByID *uint      `json:"voided_by_id,omitempty"`
+	VoidedBy   *User      `gorm:"foreignKey:VoidedByID" json:"-"`
+	VoidReason string     `json:"void_reason,omitempty"`
 }
 
 type TransactionItem struct {
@@ -53,8 +238,501 @@ type TransactionItem struct {
 	Transaction   Transaction `gorm:"foreignKey:TransactionID" json:"-"`
 	ProductID     uint        `gorm:"not null" json:"product_id"`
 	Product       Product     `gorm:"foreignKey:ProductID" json:"product"`
-	Quantity      int         `gorm:"not null" json:"quantity"`
-	Subtotal      float64     `gorm:"not null" json:"subtotal"`
+
+	// Snapshot saat penjualan, supaya laporan lama tidak berubah ketika harga/resep diubah
+	ProductName string  `gorm:"not null;default:''" json:"product_name"`
+	UnitPrice   float64 `gorm:"not null;default:0" json:"unit_price"`
+	UnitCost    float64 `gorm:"not null;default:0" json:"unit_cost"`
+
+	Quantity         int     `gorm:"not null" json:"quantity"`
+	Subtotal         float64 `gorm:"not null" json:"subtotal"`
+	DiscountAmount   float64 `gorm:"not null;default:0" json:"discount_amount"` // bagian diskon untuk baris ini
+	RefundedQuantity int     `gorm:"not null;default:0" json:"refunded_quantity"`
+
+	// UnitPrice dan UnitCost di atas sudah termasuk modifier yang dipilih
+	Modifiers []TransactionItemModifier `gorm:"foreignKey:TransactionItemID" json:"modifiers,omitempty"`
+}
+
+// TransactionItemModifier is a snapshot of a modifier option chosen for a sold item
+type TransactionItemModifier struct {
+	ID                uint    `gorm:"primaryKey" json:"id"`
+	TransactionItemID uint    `gorm:"not null;index" json:"transaction_item_id"`
+	ModifierOptionID  *uint   `json:"modifier_option_id"` // null jika opsi sudah dihapus
+	Name              string  `gorm:"not null" json:"name"`
+	PriceDelta        float64 `gorm:"not null;default:0" json:"price_delta"`
+	UnitCost          float64 `gorm:"not null;default:0" json:"unit_cost"`
+}
+
+// TransactionDiscount records a promotion or voucher applied to a transaction
+type TransactionDiscount struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	TransactionID uint    `gorm:"not null;index" json:"transaction_id"`
+	PromotionID   *uint   `gorm:"index" json:"promotion_id"`
+	VoucherID     *uint   `json:"voucher_id,omitempty"`
+	Name          string  `gorm:"not null" json:"name"` // snapshot nama promo
+	Code          string  `json:"code,omitempty"`       // kode voucher jika ada
+	Amount        float64 `gorm:"not null" json:"amount"`
+}
+
+// TransactionPayment is one tender of a transaction, e.g. part cash and part QRIS.
+// Amount adalah bagian tagihan yang dibayar; untuk tunai Tendered = uang diterima dan Change = kembalian.
+type TransactionPayment struct {
+	ID              uint    `gorm:"primaryKey" json:"id"`
+	TransactionID   uint    `gorm:"not null;index" json:"transaction_id"`
+	PaymentMethodID uint    `gorm:"not null" json:"payment_method_id"`
+	PaymentMethod   string  `gorm:"not null" json:"payment_method"` // snapshot kode metode
+	Amount          float64 `gorm:"not null" json:"amount"`
+	Tendered        float64 `gorm:"not null;default:0" json:"tendered"`
+	ChangeAmount    float64 `gorm:"not null;default:0" json:"change"`
+}
+
+// TransactionCharge is a snapshot of a tax or service charge rule applied to a transaction
+type TransactionCharge struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TransactionID uint       `gorm:"not null;index" json:"transaction_id"`
+	TaxRuleID     *uint      `gorm:"index" json:"tax_rule_id"`
+	Name          string     `gorm:"not null" json:"name"`
+	Kind          ChargeKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Rate          float64    `gorm:"not null" json:"rate"`
+	Inclusive     bool       `gorm:"not null" json:"inclusive"`
+	Base          float64    `gorm:"not null" json:"base"` // dasar pengenaan
+	Amount        float64    `gorm:"not null" json:"amount"`
+}
+
+// Refund mencatat pengembalian dana (penuh atau sebagian) atas sebuah transaksi
+type Refund struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	TransactionID uint         `gorm:"not null;index" json:"transaction_id"`
+	Transaction   Transaction  `gorm:"foreignKey:TransactionID" json:"-"`
+	Amount        float64      `gorm:"not null" json:"amount"`
+	PaymentMethod string       `gorm:"not null;default:''" json:"payment_method"` // metode pengembalian dana
+	Reason        string       `gorm:"not null" json:"reason"`
+	UserID        uint         `gorm:"not null" json:"user_id"`
+	User          User         `gorm:"foreignKey:UserID" json:"-"`
+	ShiftID       *uint        `gorm:"index" json:"shift_id"`
+	Items         []RefundItem `gorm:"foreignKey:RefundID" json:"items"`
+	CreatedAt     time.Time    `gorm:"default:now()" json:"created_at"`
+}
+
+type RefundItem struct {
+	ID                uint    `gorm:"primaryKey" json:"id"`
+	RefundID          uint    `gorm:"not null;index" json:"refund_id"`
+	TransactionItemID uint    `gorm:"not null" json:"transaction_item_id"`
+	ProductID         uint    `gorm:"not null" json:"product_id"`
+	Quantity          int     `gorm:"not null" json:"quantity"`
+	Amount            float64 `gorm:"not null" json:"amount"`
+}
+
+// ==========================================
+// PAYMENT METHODS
+// ==========================================
+
+// PaymentMethod is an accepted way to pay, managed by the admin (bukan teks bebas).
+// IsCash menandai metode yang masuk laci kasir dan boleh ada kembalian.
+type PaymentMethod struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Code         string    `gorm:"type:varchar(50);not null;unique" json:"code"`
+	Name         string    `gorm:"not null" json:"name"`
+	IsCash       bool      `gorm:"not null;default:false" json:"is_cash"`
+	IsActive     bool      `gorm:"not null;default:true" json:"is_active"`
+	DisplayOrder int       `gorm:"not null;default:0" json:"display_order"`
+	CreatedAt    time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+// ==========================================
+// TAX & SERVICE CHARGE
+// ==========================================
+
+type ChargeKind string
+
+const (
+	ChargeKindServiceCharge ChargeKind = "service_charge"
+	ChargeKindTax           ChargeKind = "tax" // mis. PB1 10%
+)
+
+// TaxRule is a configurable tax or service charge, as a percentage of the sale.
+// Inclusive berarti sudah termasuk dalam harga menu; exclusive ditambahkan di atas harga.
+// Pajak dihitung dari harga setelah diskon ditambah service charge.
+type TaxRule struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Name         string     `gorm:"not null" json:"name"`
+	Kind         ChargeKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Rate         float64    `gorm:"not null" json:"rate"` // persen
+	Inclusive    bool       `gorm:"not null;default:false" json:"inclusive"`
+	IsActive     bool       `gorm:"not null;default:true" json:"is_active"`
+	DisplayOrder int        `gorm:"not null;default:0" json:"display_order"`
+	CreatedAt    time.Time  `gorm:"default:now()" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"default:now()" json:"updated_at"`
+}
+
+// ==========================================
+// PROMOTIONS & VOUCHERS
+// ==========================================
+
+type PromotionType string
+
+const (
+	PromotionPercentage PromotionType = "percentage"  // Value = persen potongan
+	PromotionFixed      PromotionType = "fixed"       // Value = potongan dalam rupiah
+	PromotionBuyXGetY   PromotionType = "buy_x_get_y" // beli BuyQuantity gratis GetQuantity (yang termurah)
+)
+
+type PromotionScope string
+
+const (
+	PromotionScopeOrder    PromotionScope = "order"
+	PromotionScopeProduct  PromotionScope = "product"
+	PromotionScopeCategory PromotionScope = "category"
+)
+
+// Promotion is a discount rule. Promo dengan AutoApply langsung berlaku untuk
+// setiap transaksi yang memenuhi syarat, selain itu hanya lewat kode voucher.
+type Promotion struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Type        PromotionType  `gorm:"type:varchar(20);not null" json:"type"`
+	Value       float64        `gorm:"not null;default:0" json:"value"`
+	BuyQuantity int            `gorm:"not null;default:0" json:"buy_quantity"`
+	GetQuantity int            `gorm:"not null;default:0" json:"get_quantity"`
+	Scope       PromotionScope `gorm:"type:varchar(20);not null;default:'order'" json:"scope"`
+	ProductID   *uint          `json:"product_id"`
+	CategoryID  *uint          `json:"category_id"`
+	MinSubtotal float64        `gorm:"not null;default:0" json:"min_subtotal"`
+	AutoApply   bool           `gorm:"not null;default:false" json:"auto_apply"`
+	IsActive    bool           `gorm:"not null;default:true" json:"is_active"`
+
+	// Periode berlaku dan jam harian (mis. happy hour 14:00-17:00), kosong = tanpa batas
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	DailyStartTime string     `gorm:"type:varchar(5);not null;default:''" json:"daily_start_time"`
+	DailyEndTime   string     `gorm:"type:varchar(5);not null;default:''" json:"daily_end_time"`
+
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+// Voucher is a code that unlocks a promotion. MaxUses 1 = sekali pakai, 0 = tanpa batas.
+type Voucher struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PromotionID uint       `gorm:"not null;index" json:"promotion_id"`
+	Promotion   *Promotion `gorm:"foreignKey:PromotionID" json:"promotion,omitempty"`
+	Code        string     `gorm:"not null;unique" json:"code"`
+	MaxUses     int        `gorm:"not null;default:1" json:"max_uses"`
+	UsedCount   int        `gorm:"not null;default:0" json:"used_count"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	IsActive    bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time  `gorm:"default:now()" json:"created_at"`
+}
+
+// ==========================================
+// CUSTOMERS & LOYALTY
+// ==========================================
+
+// Customer is an optional customer profile attached to transactions at the POS
+type Customer struct {
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Phone string `gorm:"not null;unique" json:"phone"` // dinormalisasi, mis. 081234567890
+	Name  string `gorm:"not null" json:"name"`
+	Email string `json:"email"`
+
+	// Saldo poin = jumlah semua LoyaltyPointEntry milik pelanggan
+	PointsBalance int `gorm:"not null;default:0" json:"points_balance"`
+
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+type LoyaltyEntryType string
+
+const (
+	LoyaltyEntryEarn   LoyaltyEntryType = "earn"
+	LoyaltyEntryRedeem LoyaltyEntryType = "redeem"
+	LoyaltyEntryVoid   LoyaltyEntryType = "void"   // pembalikan karena transaksi di-void
+	LoyaltyEntryRefund LoyaltyEntryType = "refund" // pembalikan proporsional karena refund
+	LoyaltyEntryAdjust LoyaltyEntryType = "adjust" // koreksi/hadiah manual oleh admin
+)
+
+// LoyaltyPointEntry is one change to a customer's points balance (positif = bertambah)
+type LoyaltyPointEntry struct {
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	CustomerID    uint             `gorm:"not null;index" json:"customer_id"`
+	TransactionID *uint            `gorm:"index" json:"transaction_id,omitempty"`
+	Type          LoyaltyEntryType `gorm:"type:varchar(20);not null" json:"type"`
+	Points        int              `gorm:"not null" json:"points"`
+	Note          string           `json:"note"`
+	UserID        *uint            `json:"user_id,omitempty"`
+	CreatedAt     time.Time        `gorm:"default:now()" json:"created_at"`
+}
+
+// ==========================================
+// ONLINE ORDERS
+// ==========================================
+
+type OnlineOrderStatus string
+
+const (
+	OnlineOrderPending   OnlineOrderStatus = "pending"
+	OnlineOrderConfirmed OnlineOrderStatus = "confirmed"
+	OnlineOrderRejected  OnlineOrderStatus = "rejected"
+)
+
+// OnlineOrder is a pickup order placed by a customer from the website.
+// Stok belum dipotong sampai staf mengonfirmasi; saat dikonfirmasi pesanan
+// dicatat sebagai Transaction lewat checkout yang sama dengan kasir.
+type OnlineOrder struct {
+	ID            uint              `gorm:"primaryKey" json:"id"`
+	Code          string            `gorm:"not null;unique" json:"code"` // dipakai pelanggan untuk cek status
+	CustomerName  string            `gorm:"not null" json:"customer_name"`
+	CustomerPhone string            `gorm:"not null" json:"customer_phone"`
+	Note          string            `json:"note"`
+	PickupTime    time.Time         `gorm:"not null" json:"pickup_time"`
+	Status        OnlineOrderStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Items         []OnlineOrderItem `gorm:"foreignKey:OnlineOrderID" json:"items,omitempty"`
+
+	// Perkiraan saat pesanan dibuat; total akhir dihitung saat dikonfirmasi
+	EstimatedTotal float64 `gorm:"not null;default:0" json:"estimated_total"`
+
+	TransactionID *uint        `json:"transaction_id,omitempty"`
+	Transaction   *Transaction `gorm:"foreignKey:TransactionID" json:"-"`
+	ReviewedByID  *uint        `json:"reviewed_by_id,omitempty"`
+	ReviewedAt    *time.Time   `json:"reviewed_at,omitempty"`
+	RejectReason  string       `json:"reject_reason,omitempty"`
+
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+type OnlineOrderItem struct {
+	ID            uint                      `gorm:"primaryKey" json:"id"`
+	OnlineOrderID uint                      `gorm:"not null;index" json:"online_order_id"`
+	ProductID     uint                      `gorm:"not null" json:"product_id"`
+	ProductName   string                    `gorm:"not null" json:"product_name"`
+	Quantity      int                       `gorm:"not null" json:"quantity"`
+	UnitPrice     float64                   `gorm:"not null" json:"unit_price"` // termasuk modifier
+	Modifiers     []OnlineOrderItemModifier `gorm:"foreignKey:OnlineOrderItemID" json:"modifiers,omitempty"`
+}
+
+// OnlineOrderItemModifier is a modifier option chosen by the customer
+type OnlineOrderItemModifier struct {
+	ID                uint    `gorm:"primaryKey" json:"id"`
+	OnlineOrderItemID uint    `gorm:"not null;index" json:"online_order_item_id"`
+	ModifierOptionID  *uint   `json:"modifier_option_id"` // null jika opsi sudah dihapus
+	Name              string  `gorm:"not null" json:"name"`
+	PriceDelta        float64 `gorm:"not null;default:0" json:"price_delta"`
+}
+
+// ==========================================
+// PURCHASING
+// ==========================================
+
+type Supplier struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null;unique" json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderOrdered           PurchaseOrderStatus = "ordered"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	SupplierID     uint                `gorm:"not null;index" json:"supplier_id"`
+	Supplier       Supplier            `gorm:"foreignKey:SupplierID" json:"supplier"`
+	Status         PurchaseOrderStatus `gorm:"type:varchar(20);not null;default:'ordered'" json:"status"`
+	OrderDate      time.Time           `gorm:"not null" json:"order_date"`
+	Notes          string              `json:"notes"`
+	TotalAmount    float64             `gorm:"not null;default:0" json:"total_amount"`    // nilai yang dipesan
+	ReceivedAmount float64             `gorm:"not null;default:0" json:"received_amount"` // nilai yang sudah diterima
+	CreatedByID    uint                `gorm:"not null" json:"created_by_id"`
+	CreatedBy      User                `gorm:"foreignKey:CreatedByID" json:"-"`
+
+	Lines    []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID" json:"lines"`
+	Receipts []PurchaseReceipt   `gorm:"foreignKey:PurchaseOrderID" json:"receipts,omitempty"`
+
+	CreatedAt time.Time `gorm:"default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
+}
+
+type PurchaseOrderLine struct {
+	ID               uint          `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID  uint          `gorm:"not null;index" json:"purchase_order_id"`
+	InventoryItemID  uint          `gorm:"not null" json:"inventory_item_id"`
+	InventoryItem    InventoryItem `gorm:"foreignKey:InventoryItemID" json:"inventory_item"`
+	QuantityOrdered  float64       `gorm:"not null" json:"quantity_ordered"`
+	QuantityReceived float64       `gorm:"not null;default:0" json:"quantity_received"`
+	UnitPrice        float64       `gorm:"not null" json:"unit_price"`
+}
+
+// PurchaseReceipt records one delivery (full or partial) against a purchase order
+type PurchaseReceipt struct {
+	ID              uint                  `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID uint                  `gorm:"not null;index" json:"purchase_order_id"`
+	InvoiceNumber   string                `json:"invoice_number"`
+	Amount          float64               `gorm:"not null" json:"amount"`
+	UserID          uint                  `gorm:"not null" json:"user_id"`
+	User            User                  `gorm:"foreignKey:UserID" json:"-"`
+	Notes           string                `json:"notes"`
+	Items           []PurchaseReceiptItem `gorm:"foreignKey:PurchaseReceiptID" json:"items"`
+	ReceivedAt      time.Time             `gorm:"default:now();index" json:"received_at"`
+}
+
+type PurchaseReceiptItem struct {
+	ID                  uint    `gorm:"primaryKey" json:"id"`
+	PurchaseReceiptID   uint    `gorm:"not null;index" json:"purchase_receipt_id"`
+	PurchaseOrderLineID uint    `gorm:"not null" json:"purchase_order_line_id"`
+	InventoryItemID     uint    `gorm:"not null" json:"inventory_item_id"`
+	Quantity            float64 `gorm:"not null" json:"quantity"`
+	UnitPrice           float64 `gorm:"not null" json:"unit_price"`
+}
+
+// ==========================================
+// STOCK OPNAME
+// ==========================================
+
+type StockCountStatus string
+
+const (
+	StockCountInProgress StockCountStatus = "in_progress"
+	StockCountCompleted  StockCountStatus = "completed"
+	StockCountCancelled  StockCountStatus = "cancelled"
+)
+
+// StockCount is a physical stock-take session. Setelah disetujui, selisih setiap
+// item dicatat sebagai stock movement dan sesi disimpan sebagai laporan.
+type StockCount struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Status      StockCountStatus `gorm:"type:varchar(20);not null;default:'in_progress'" json:"status"`
+	Notes       string           `json:"notes"`
+	StartedByID uint             `gorm:"not null" json:"started_by_id"`
+	StartedBy   User             `gorm:"foreignKey:StartedByID" json:"-"`
+	StartedAt   time.Time        `gorm:"default:now()" json:"started_at"`
+
+	// Diisi saat disetujui atau dibatalkan
+	ClosedByID *uint      `json:"closed_by_id,omitempty"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+
+	Items []StockCountItem `gorm:"foreignKey:StockCountID" json:"items"`
+}
+
+// StockCountItem is the counted quantity of one inventory item.
+// SystemQuantity adalah stok sistem saat item dihitung, sehingga penjualan yang
+// terjadi selama opname tidak dianggap sebagai selisih.
+type StockCountItem struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	StockCountID    uint       `gorm:"not null;index" json:"stock_count_id"`
+	InventoryItemID uint       `gorm:"not null" json:"inventory_item_id"`
+	Name            string     `gorm:"not null" json:"name"` // snapshot nama & satuan
+	Unit            string     `gorm:"not null" json:"unit"`
+	SystemQuantity  float64    `gorm:"not null;default:0" json:"system_quantity"`
+	CountedQuantity *float64   `json:"counted_quantity"`                   // null = belum dihitung
+	Variance        float64    `gorm:"not null;default:0" json:"variance"` // counted - system
+	CostPerUnit     float64    `gorm:"not null;default:0" json:"cost_per_unit"`
+	VarianceValue   float64    `gorm:"not null;default:0" json:"variance_value"`
+	Note            string     `json:"note"`
+	CountedByID     *uint      `json:"counted_by_id,omitempty"`
+	CountedAt       *time.Time `json:"counted_at,omitempty"`
+}
+
+// ==========================================
+// WASTE & SPOILAGE
+// ==========================================
+
+type WasteReason string
+
+const (
+	WasteExpired   WasteReason = "expired"
+	WasteDropped   WasteReason = "dropped"
+	WasteBurnt     WasteReason = "burnt"
+	WasteStaffMeal WasteReason = "staff_meal"
+)
+
+// Valid reports whether the reason is one of the known reason codes
+func (r WasteReason) Valid() bool {
+	switch r {
+	case WasteExpired, WasteDropped, WasteBurnt, WasteStaffMeal:
+		return true
+	}
+	return false
+}
+
+// WasteRecord is stock thrown away: either an inventory item (mis. selai kedaluwarsa)
+// or a finished product, whose recipe ingredients are deducted from stock
+type WasteRecord struct {
+	ID              uint        `gorm:"primaryKey" json:"id"`
+	InventoryItemID *uint       `gorm:"index" json:"inventory_item_id,omitempty"`
+	ProductID       *uint       `gorm:"index" json:"product_id,omitempty"`
+	Name            string      `gorm:"not null" json:"name"` // snapshot nama bahan/produk
+	Unit            string      `gorm:"not null" json:"unit"`
+	Quantity        float64     `gorm:"not null" json:"quantity"`
+	Reason          WasteReason `gorm:"type:varchar(20);not null" json:"reason"`
+	Note            string      `json:"note"`
+	CostAmount      float64     `gorm:"not null;default:0" json:"cost_amount"` // nilai bahan baku saat dicatat
+	UserID          uint        `gorm:"not null" json:"user_id"`
+	User            User        `gorm:"foreignKey:UserID" json:"-"`
+	RecordedAt      time.Time   `gorm:"default:now();index" json:"recorded_at"`
+}
+
+// ==========================================
+// CASH REGISTER SHIFTS
+// ==========================================
+
+type ShiftStatus string
+
+const (
+	ShiftStatusOpen   ShiftStatus = "open"
+	ShiftStatusClosed ShiftStatus = "closed"
+)
+
+type Shift struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	UserID      uint        `gorm:"not null;index" json:"user_id"`
+	User        User        `gorm:"foreignKey:UserID" json:"-"`
+	Status      ShiftStatus `gorm:"type:varchar(20);not null;default:'open'" json:"status"`
+	OpeningCash float64     `gorm:"not null;default:0" json:"opening_cash"`
+
+	// Diisi saat shift ditutup
+	ExpectedCash float64    `gorm:"not null;default:0" json:"expected_cash"`
+	CountedCash  float64    `gorm:"not null;default:0" json:"counted_cash"`
+	CashVariance float64    `gorm:"not null;default:0" json:"cash_variance"`
+	ClosedByID   *uint      `json:"closed_by_id,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	Notes        string     `json:"notes"`
+
+	Payments []ShiftPayment `gorm:"foreignKey:ShiftID" json:"payments,omitempty"`
+	OpenedAt time.Time      `gorm:"default:now()" json:"opened_at"`
+}
+
+// ShiftPayment is the expected vs. counted amount of one payment method when a shift is closed
+type ShiftPayment struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	ShiftID        uint    `gorm:"not null;index" json:"shift_id"`
+	PaymentMethod  string  `gorm:"not null" json:"payment_method"`
+	ExpectedAmount float64 `gorm:"not null" json:"expected_amount"`
+	CountedAmount  float64 `gorm:"not null" json:"counted_amount"`
+	Variance       float64 `gorm:"not null" json:"variance"`
+}
+
+// ==========================================
+// SETTINGS
+// ==========================================
+
+// Setting is a global key/value configuration entry
+type Setting struct {
+	Key       string    `gorm:"primaryKey;type:varchar(100)" json:"key"`
+	Value     string    `gorm:"not null" json:"value"`
+	UpdatedAt time.Time `gorm:"default:now()" json:"updated_at"`
 }
 
 // ==========================================
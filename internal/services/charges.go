@@ -0,0 +1,76 @@
+package services
+
+import (
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AppliedCharge is the amount of one tax or service charge rule on a sale
+type AppliedCharge struct {
+	Rule   models.TaxRule
+	Base   float64
+	Amount float64
+}
+
+// ChargeBreakdown is the result of applying the tax and service charge rules to a sale
+type ChargeBreakdown struct {
+	ServiceCharge float64
+	Tax           float64
+	Total         float64 // yang dibayar pelanggan
+	Charges       []AppliedCharge
+}
+
+// ActiveTaxRules returns the tax and service charge rules that apply to new sales
+func ActiveTaxRules(db *gorm.DB) ([]models.TaxRule, error) {
+	var rules []models.TaxRule
+	err := db.Where("is_active = ?", true).Order("display_order, id").Find(&rules).Error
+	return rules, err
+}
+
+// CalculateCharges applies the rules to amount, the sale price after discounts.
+//
+// Service charge dihitung dari harga dasar, pajak dari harga dasar + service charge
+// (seperti PB1). Aturan inclusive sudah termasuk di amount sehingga harga dasar
+// dihitung mundur: amount = dasar * (1 + S_incl + (1 + S) * T_incl).
+func CalculateCharges(rules []models.TaxRule, amount float64) ChargeBreakdown {
+	var serviceRate, inclusiveServiceRate, inclusiveTaxRate float64
+	for _, rule := range rules {
+		switch rule.Kind {
+		case models.ChargeKindServiceCharge:
+			serviceRate += rule.Rate / 100
+			if rule.Inclusive {
+				inclusiveServiceRate += rule.Rate / 100
+			}
+		case models.ChargeKindTax:
+			if rule.Inclusive {
+				inclusiveTaxRate += rule.Rate / 100
+			}
+		}
+	}
+
+	base := amount / (1 + inclusiveServiceRate + (1+serviceRate)*inclusiveTaxRate)
+	taxBase := base * (1 + serviceRate)
+
+	breakdown := ChargeBreakdown{Total: amount}
+	for _, rule := range rules {
+		charge := AppliedCharge{Rule: rule, Base: RoundMoney(base)}
+		switch rule.Kind {
+		case models.ChargeKindServiceCharge:
+			charge.Amount = RoundMoney(base * rule.Rate / 100)
+			breakdown.ServiceCharge += charge.Amount
+		case models.ChargeKindTax:
+			charge.Base = RoundMoney(taxBase)
+			charge.Amount = RoundMoney(taxBase * rule.Rate / 100)
+			breakdown.Tax += charge.Amount
+		default:
+			continue
+		}
+		if !rule.Inclusive {
+			breakdown.Total += charge.Amount
+		}
+		breakdown.Charges = append(breakdown.Charges, charge)
+	}
+	breakdown.Total = RoundMoney(breakdown.Total)
+	return breakdown
+}
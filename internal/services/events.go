@@ -0,0 +1,113 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+)
+
+// EventType identifies a domain event published to the live event stream
+type EventType string
+
+const (
+	EventTransactionCreated EventType = "transaction.created"
+	EventStockChanged       EventType = "stock.changed"
+	EventLowStock           EventType = "stock.low"
+	EventOrderStatusChanged EventType = "order.status_changed"
+	EventOnlineOrderCreated EventType = "online_order.created"
+	EventOnlineOrderUpdated EventType = "online_order.updated"
+)
+
+// eventRoles menentukan role yang boleh menerima tiap jenis event
+var eventRoles = map[EventType][]models.Role{
+	EventTransactionCreated: {models.RoleAdmin, models.RoleKasir},
+	EventStockChanged:       {models.RoleAdmin, models.RoleKaryawan},
+	EventLowStock:           {models.RoleAdmin, models.RoleKaryawan, models.RoleKasir},
+	EventOrderStatusChanged: {models.RoleAdmin, models.RoleKaryawan, models.RoleKasir},
+	EventOnlineOrderCreated: {models.RoleAdmin, models.RoleKasir},
+	EventOnlineOrderUpdated: {models.RoleAdmin, models.RoleKasir},
+}
+
+// Event is a domain event; Data is serialized to JSON for subscribers
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type EventType   `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// VisibleTo reports whether a user with the given role may receive the event
+func (e Event) VisibleTo(role models.Role) bool {
+	for _, allowed := range eventRoles[e.Type] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// eventBufferSize adalah jumlah event yang boleh tertunda per subscriber;
+// subscriber yang terlalu lambat akan kehilangan event, bukan menahan publisher
+const eventBufferSize = 64
+
+// EventBus fans out domain events to the connected subscribers (in-memory, satu proses)
+type EventBus struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	subscribers map[chan Event]struct{}
+}
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Events is the event bus of the running server
+var Events = NewEventBus()
+
+// Subscribe registers a new subscriber and returns its channel together with a
+// function that must be called to unsubscribe
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to every subscriber without blocking.
+// Panggil setelah DB transaction di-commit supaya event tidak mendahului datanya.
+func (b *EventBus) Publish(eventType EventType, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := Event{ID: b.nextID, Type: eventType, Time: time.Now(), Data: data}
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// PublishStockLevels publishes stock.changed for each updated item, and stock.low
+// for items that are at or below their low stock threshold
+func (b *EventBus) PublishStockLevels(items []models.InventoryItem) {
+	for _, item := range items {
+		b.Publish(EventStockChanged, item)
+		if item.LowStockThreshold > 0 && item.StockLevel <= item.LowStockThreshold {
+			b.Publish(EventLowStock, item)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+// Setting keys of the loyalty program
+const (
+	// Belanja (rupiah) untuk mendapat 1 poin; 0 berarti tidak ada poin yang didapat
+	SettingLoyaltyEarnAmount = "loyalty_earn_amount"
+	// Nilai rupiah 1 poin saat ditukar sebagai diskon; 0 berarti penukaran dimatikan
+	SettingLoyaltyPointValue = "loyalty_point_value"
+	// Minimal poin untuk sekali penukaran
+	SettingLoyaltyMinRedeem = "loyalty_min_redeem"
+)
+
+// LoyaltyRules are the configured rules for earning and redeeming points
+type LoyaltyRules struct {
+	EarnAmount float64
+	PointValue float64
+	MinRedeem  int
+}
+
+// GetLoyaltyRules reads the loyalty rules from the settings.
+// Default: 1 poin per Rp10.000, 1 poin = Rp100, minimal tukar 10 poin.
+func GetLoyaltyRules(db *gorm.DB) LoyaltyRules {
+	rules := LoyaltyRules{EarnAmount: 10000, PointValue: 100, MinRedeem: 10}
+	if v, err := strconv.ParseFloat(GetSetting(db, SettingLoyaltyEarnAmount, ""), 64); err == nil && v >= 0 {
+		rules.EarnAmount = v
+	}
+	if v, err := strconv.ParseFloat(GetSetting(db, SettingLoyaltyPointValue, ""), 64); err == nil && v >= 0 {
+		rules.PointValue = v
+	}
+	if v, err := strconv.Atoi(GetSetting(db, SettingLoyaltyMinRedeem, "")); err == nil && v >= 0 {
+		rules.MinRedeem = v
+	}
+	return rules
+}
+
+// PointsFor returns the points earned for spending amount (dibulatkan ke bawah)
+func (r LoyaltyRules) PointsFor(amount float64) int {
+	if r.EarnAmount <= 0 || amount <= 0 {
+		return 0
+	}
+	return int(math.Floor(amount / r.EarnAmount))
+}
+
+// NormalizePhone keeps only the digits of a phone number and writes Indonesian
+// numbers in local form, so "+62 812-3456" and "08123456" are the same customer
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
+
+// LoyaltyService keeps customer point balances and their ledger in sync
+type LoyaltyService struct {
+	DB *gorm.DB
+}
+
+// NewLoyaltyService creates a loyalty service on the given handle.
+// Berikan tx supaya perubahan poin ikut transaksi penjualan.
+func NewLoyaltyService(db *gorm.DB) *LoyaltyService {
+	return &LoyaltyService{DB: db}
+}
+
+// AddPoints records the entry and updates the customer's balance. Entry dengan
+// poin negatif yang melebihi saldo ditolak dengan ErrInsufficientPoints.
+func (s *LoyaltyService) AddPoints(entry models.LoyaltyPointEntry) (models.LoyaltyPointEntry, error) {
+	return s.apply(entry, false)
+}
+
+// ReversePoints takes back points for a void or refund. Poin yang sudah terpakai
+// tidak bisa ditarik lagi, jadi pemotongan dibatasi sampai saldo habis.
+func (s *LoyaltyService) ReversePoints(entry models.LoyaltyPointEntry) (models.LoyaltyPointEntry, error) {
+	return s.apply(entry, true)
+}
+
+func (s *LoyaltyService) apply(entry models.LoyaltyPointEntry, capAtBalance bool) (models.LoyaltyPointEntry, error) {
+	if entry.Points == 0 {
+		return entry, nil
+	}
+
+	var customer models.Customer
+	if err := s.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, entry.CustomerID).Error; err != nil {
+		return entry, err
+	}
+	if customer.PointsBalance+entry.Points < 0 {
+		if !capAtBalance {
+			return entry, ErrInsufficientPoints
+		}
+		entry.Points = -customer.PointsBalance
+		if entry.Points == 0 {
+			return entry, nil
+		}
+	}
+
+	if err := s.DB.Create(&entry).Error; err != nil {
+		return entry, err
+	}
+	err := s.DB.Model(&customer).Update("points_balance", gorm.Expr("points_balance + ?", entry.Points)).Error
+	return entry, err
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NextQueueNumber returns the next customer queue number for the day of t.
+// Counter per hari di-upsert secara atomik dan row-nya terkunci sampai transaksi
+// selesai, sehingga dua kasir yang checkout bersamaan tidak mendapat nomor yang sama.
+func NextQueueNumber(db *gorm.DB, t time.Time) (int, error) {
+	var number int
+	err := db.Raw(`INSERT INTO order_queue_counters (queue_date, last_number) VALUES (?, 1)
+		ON CONFLICT (queue_date) DO UPDATE SET last_number = order_queue_counters.last_number + 1
+		RETURNING last_number`, t.Format("2006-01-02")).Scan(&number).Error
+	return number, err
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoucherError is returned when a voucher code cannot be used for a checkout
+type VoucherError struct {
+	Reason string
+}
+
+func (e *VoucherError) Error() string {
+	return e.Reason
+}
+
+// CartLine is one line of a checkout, used to calculate promotions.
+// UnitPrice sudah termasuk modifier.
+type CartLine struct {
+	ProductID  uint
+	CategoryID *uint
+	Quantity   int
+	UnitPrice  float64
+}
+
+// AppliedDiscount is a promotion applied to a cart and the amount it takes off each line
+type AppliedDiscount struct {
+	Promotion   models.Promotion
+	Voucher     *models.Voucher
+	Amount      float64
+	LineAmounts []float64 // sejajar dengan CartLine yang diberikan
+}
+
+// PromotionService calculates the discounts for a checkout
+type PromotionService struct {
+	DB *gorm.DB
+}
+
+// NewPromotionService creates a promotion service on the given handle.
+// Berikan tx supaya pemakaian voucher ikut transaksi penjualan.
+func NewPromotionService(db *gorm.DB) *PromotionService {
+	return &PromotionService{DB: db}
+}
+
+// NormalizeVoucherCode makes voucher codes case-insensitive
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Apply calculates every automatic promotion that is active at now, plus the
+// promotion of voucherCode if given, and counts the voucher as used.
+// Promo dihitung berurutan dari sisa harga setiap baris, sehingga total diskon
+// tidak pernah melebihi harga baris. Voucher selalu dihitung paling akhir.
+func (s *PromotionService) Apply(lines []CartLine, voucherCode string, now time.Time) ([]AppliedDiscount, error) {
+	var promotions []models.Promotion
+	if err := s.DB.Where("auto_apply = ? AND is_active = ?", true, true).Order("id").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+
+	remaining := make([]float64, len(lines))
+	for i, line := range lines {
+		remaining[i] = line.UnitPrice * float64(line.Quantity)
+	}
+
+	var applied []AppliedDiscount
+	for _, promotion := range promotions {
+		if !promotionActiveAt(promotion, now) {
+			continue
+		}
+		if discount, ok := calculateDiscount(promotion, lines, remaining); ok {
+			applied = append(applied, discount)
+		}
+	}
+
+	if code := NormalizeVoucherCode(voucherCode); code != "" {
+		discount, err := s.applyVoucher(code, lines, remaining, now)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, discount)
+	}
+	return applied, nil
+}
+
+// applyVoucher mengunci voucher, memvalidasinya, lalu menambah used_count
+func (s *PromotionService) applyVoucher(code string, lines []CartLine, remaining []float64, now time.Time) (AppliedDiscount, error) {
+	var voucher models.Voucher
+	if err := s.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&voucher).Error; err != nil {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher code not found"}
+	}
+	var promotion models.Promotion
+	if err := s.DB.First(&promotion, voucher.PromotionID).Error; err != nil {
+		return AppliedDiscount{}, err
+	}
+	voucher.Promotion = &promotion
+
+	if !voucher.IsActive || !promotion.IsActive {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher is no longer active"}
+	}
+	if voucher.ExpiresAt != nil && now.After(*voucher.ExpiresAt) {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher has expired"}
+	}
+	if voucher.MaxUses > 0 && voucher.UsedCount >= voucher.MaxUses {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher has already been used"}
+	}
+	if !promotionActiveAt(promotion, now) {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher cannot be used at this time"}
+	}
+
+	discount, ok := calculateDiscount(promotion, lines, remaining)
+	if !ok {
+		return AppliedDiscount{}, &VoucherError{Reason: "Voucher does not apply to this order"}
+	}
+
+	if err := s.DB.Model(&voucher).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return AppliedDiscount{}, err
+	}
+	voucher.UsedCount++
+	discount.Voucher = &voucher
+	return discount, nil
+}
+
+// ReleaseVoucher gives back one use of a voucher, e.g. when its sale is voided
+func (s *PromotionService) ReleaseVoucher(voucherID uint) error {
+	return s.DB.Model(&models.Voucher{}).
+		Where("id = ? AND used_count > 0", voucherID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// promotionActiveAt checks the validity period and the daily time window.
+// Jam harian yang melewati tengah malam (mis. 22:00-02:00) juga didukung.
+func promotionActiveAt(p models.Promotion, now time.Time) bool {
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && now.After(*p.EndsAt) {
+		return false
+	}
+	if p.DailyStartTime == "" || p.DailyEndTime == "" {
+		return true
+	}
+
+	clock := now.Format("15:04")
+	if p.DailyStartTime <= p.DailyEndTime {
+		return clock >= p.DailyStartTime && clock < p.DailyEndTime
+	}
+	return clock >= p.DailyStartTime || clock < p.DailyEndTime
+}
+
+// promotionCoversLine reports whether a cart line is in the scope of a promotion
+func promotionCoversLine(p models.Promotion, line CartLine) bool {
+	switch p.Scope {
+	case models.PromotionScopeProduct:
+		return p.ProductID != nil && *p.ProductID == line.ProductID
+	case models.PromotionScopeCategory:
+		return p.CategoryID != nil && line.CategoryID != nil && *p.CategoryID == *line.CategoryID
+	}
+	return true
+}
+
+// calculateDiscount computes the discount of one promotion on the remaining
+// line amounts and subtracts it from remaining. ok=false jika promo tidak berlaku.
+func calculateDiscount(p models.Promotion, lines []CartLine, remaining []float64) (AppliedDiscount, bool) {
+	var scoped []int
+	var scopedTotal float64
+	for i, line := range lines {
+		if promotionCoversLine(p, line) && remaining[i] > 0 {
+			scoped = append(scoped, i)
+			scopedTotal += remaining[i]
+		}
+	}
+	if len(scoped) == 0 || scopedTotal < p.MinSubtotal {
+		return AppliedDiscount{}, false
+	}
+
+	lineAmounts := make([]float64, len(lines))
+	switch p.Type {
+	case models.PromotionPercentage:
+		for _, i := range scoped {
+			lineAmounts[i] = RoundMoney(remaining[i] * math.Min(p.Value, 100) / 100)
+		}
+
+	case models.PromotionFixed:
+		// Potongan dibagi proporsional ke setiap baris, sisa pembulatan ke baris terakhir
+		amount := math.Min(p.Value, scopedTotal)
+		allocated := 0.0
+		for n, i := range scoped {
+			if n == len(scoped)-1 {
+				lineAmounts[i] = RoundMoney(amount - allocated)
+				break
+			}
+			lineAmounts[i] = RoundMoney(amount * remaining[i] / scopedTotal)
+			allocated += lineAmounts[i]
+		}
+
+	case models.PromotionBuyXGetY:
+		// Setiap BuyQuantity+GetQuantity unit, GetQuantity unit termurah gratis
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return AppliedDiscount{}, false
+		}
+		// Dihitung per baris (bukan per unit) supaya jumlah besar tidak memakan memori
+		cheapest := make([]int, len(scoped))
+		copy(cheapest, scoped)
+		units := 0
+		for _, i := range scoped {
+			units += lines[i].Quantity
+		}
+		sort.SliceStable(cheapest, func(a, b int) bool {
+			return remaining[cheapest[a]]/float64(lines[cheapest[a]].Quantity) <
+				remaining[cheapest[b]]/float64(lines[cheapest[b]].Quantity)
+		})
+		free := units / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+		for _, i := range cheapest {
+			if free <= 0 {
+				break
+			}
+			n := min(free, lines[i].Quantity)
+			lineAmounts[i] = RoundMoney(remaining[i] / float64(lines[i].Quantity) * float64(n))
+			free -= n
+		}
+
+	default:
+		return AppliedDiscount{}, false
+	}
+
+	discount := AppliedDiscount{Promotion: p, LineAmounts: lineAmounts}
+	for i, amount := range lineAmounts {
+		if amount > remaining[i] {
+			amount = remaining[i]
+			lineAmounts[i] = amount
+		}
+		remaining[i] -= amount
+		discount.Amount += amount
+	}
+	if discount.Amount <= 0 {
+		return AppliedDiscount{}, false
+	}
+	return discount, true
+}
+
+// RoundMoney rounds an amount to two decimals
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
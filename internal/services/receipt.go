@@ -0,0 +1,315 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DefaultStoreName is printed on receipts until the store_name setting is filled in
+const DefaultStoreName = "Hayoon Bite"
+
+// ReceiptPaper is the width of the thermal paper roll in millimetres
+type ReceiptPaper int
+
+const (
+	ReceiptPaper58mm ReceiptPaper = 58
+	ReceiptPaper80mm ReceiptPaper = 80
+)
+
+// Valid reports whether the paper width is supported
+func (p ReceiptPaper) Valid() bool {
+	return p == ReceiptPaper58mm || p == ReceiptPaper80mm
+}
+
+// Columns returns the number of characters per line of the standard printer font
+func (p ReceiptPaper) Columns() int {
+	if p == ReceiptPaper80mm {
+		return 48
+	}
+	return 32
+}
+
+// ReceiptLine is one printed line; Text is never wider than the paper
+type ReceiptLine struct {
+	Text   string
+	Center bool
+	Bold   bool
+}
+
+// Receipt holds everything printed on a customer receipt.
+// Transaction harus sudah di-preload dengan Items.Modifiers, Discounts, Charges, Payments
+// dan Customer.
+type Receipt struct {
+	StoreName    string
+	StoreAddress string
+	Footer       string
+	Cashier      string
+	Transaction  models.Transaction
+
+	// Nama metode pembayaran per kode, mis. "qris" -> "QRIS"
+	PaymentNames map[string]string
+}
+
+// NewReceipt builds a receipt for a loaded transaction using the store settings
+func NewReceipt(db *gorm.DB, transaction models.Transaction) (Receipt, error) {
+	receipt := Receipt{
+		StoreName:    GetSetting(db, SettingStoreName, DefaultStoreName),
+		StoreAddress: GetSetting(db, SettingStoreAddress, ""),
+		Footer:       GetSetting(db, SettingReceiptFooter, "Terima kasih"),
+		Transaction:  transaction,
+		PaymentNames: make(map[string]string),
+	}
+	if transaction.User != nil {
+		receipt.Cashier = transaction.User.Username
+	}
+
+	var methods []models.PaymentMethod
+	if err := db.Find(&methods).Error; err != nil {
+		return Receipt{}, err
+	}
+	for _, method := range methods {
+		receipt.PaymentNames[method.Code] = method.Name
+	}
+	return receipt, nil
+}
+
+// Lines lays out the receipt for the given paper width
+func (r Receipt) Lines(paper ReceiptPaper) []ReceiptLine {
+	width := paper.Columns()
+	t := r.Transaction
+	var lines []ReceiptLine
+
+	add := func(text string, center, bold bool) {
+		for _, part := range wrapText(text, width) {
+			lines = append(lines, ReceiptLine{Text: part, Center: center, Bold: bold})
+		}
+	}
+	addPair := func(left, right string, bold bool) {
+		for _, part := range pairText(asciiOnly(left), asciiOnly(right), width) {
+			lines = append(lines, ReceiptLine{Text: part, Bold: bold})
+		}
+	}
+	separator := func() {
+		lines = append(lines, ReceiptLine{Text: strings.Repeat("-", width)})
+	}
+
+	// Header toko
+	add(r.StoreName, true, true)
+	for _, line := range strings.Split(r.StoreAddress, "\n") {
+		if strings.TrimSpace(line) != "" {
+			add(line, true, false)
+		}
+	}
+	separator()
+	if t.QueueNumber > 0 {
+		add(fmt.Sprintf("ANTREAN %d", t.QueueNumber), true, true)
+		separator()
+	}
+	add(fmt.Sprintf("No.   : #%d", t.ID), false, false)
+	add("Waktu : "+t.TransactionTime.Format("02/01/2006 15:04"), false, false)
+	if r.Cashier != "" {
+		add("Kasir : "+r.Cashier, false, false)
+	}
+	if t.Customer != nil {
+		add("Member: "+t.Customer.Name, false, false)
+	}
+	separator()
+
+	// Item beserta modifier (harga modifier sudah termasuk di harga satuan)
+	for _, item := range t.Items {
+		add(item.ProductName, false, false)
+		for _, modifier := range item.Modifiers {
+			add("  + "+modifier.Name, false, false)
+		}
+		addPair(fmt.Sprintf("  %d x %s", item.Quantity, formatRupiah(item.UnitPrice)), formatRupiah(item.Subtotal), false)
+	}
+	separator()
+
+	addPair("Subtotal", formatRupiah(t.Subtotal), false)
+	for _, discount := range t.Discounts {
+		label := discount.Name
+		if discount.Code != "" {
+			label += " (" + discount.Code + ")"
+		}
+		addPair(label, "-"+formatRupiah(discount.Amount), false)
+	}
+	for _, charge := range t.Charges {
+		label := fmt.Sprintf("%s %s%%", charge.Name, formatRate(charge.Rate))
+		if charge.Inclusive {
+			// Sudah termasuk di harga, hanya informasi
+			addPair(label+" (termasuk)", formatRupiah(charge.Amount), false)
+			continue
+		}
+		addPair(label, formatRupiah(charge.Amount), false)
+	}
+	addPair("TOTAL", formatRupiah(t.TotalAmount), true)
+	separator()
+
+	for _, payment := range t.Payments {
+		name := r.PaymentNames[payment.PaymentMethod]
+		if name == "" {
+			name = strings.ToUpper(payment.PaymentMethod)
+		}
+		if payment.ChangeAmount > 0 {
+			addPair(name, formatRupiah(payment.Tendered), false)
+			addPair("Kembalian", formatRupiah(payment.ChangeAmount), false)
+			continue
+		}
+		addPair(name, formatRupiah(payment.Amount), false)
+	}
+
+	if t.Customer != nil && (t.PointsEarned > 0 || t.PointsRedeemed > 0) {
+		separator()
+		if t.PointsRedeemed > 0 {
+			addPair("Poin ditukar", fmt.Sprintf("-%d", t.PointsRedeemed), false)
+		}
+		if t.PointsEarned > 0 {
+			addPair("Poin didapat", fmt.Sprintf("+%d", t.PointsEarned), false)
+		}
+	}
+
+	switch t.Status {
+	case models.TransactionStatusVoided:
+		separator()
+		add("*** VOID ***", true, true)
+		if t.VoidReason != "" {
+			add(t.VoidReason, true, false)
+		}
+	case models.TransactionStatusRefunded, models.TransactionStatusPartiallyRefunded:
+		separator()
+		addPair("Refund", "-"+formatRupiah(t.RefundedAmount), true)
+	}
+
+	if strings.TrimSpace(r.Footer) != "" {
+		separator()
+		for _, line := range strings.Split(r.Footer, "\n") {
+			add(line, true, false)
+		}
+	}
+	return lines
+}
+
+// Text renders the receipt as plain text, one printed line per text line
+func (r Receipt) Text(paper ReceiptPaper) []byte {
+	var b strings.Builder
+	for _, line := range r.Lines(paper) {
+		b.WriteString(strings.TrimRight(padLine(line, paper.Columns()), " "))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// padLine returns the text positioned within the paper width (rata tengah dengan spasi)
+func padLine(line ReceiptLine, width int) string {
+	if !line.Center || len(line.Text) >= width {
+		return line.Text
+	}
+	return strings.Repeat(" ", (width-len(line.Text))/2) + line.Text
+}
+
+// wrapText memecah teks per kata supaya muat di lebar kertas.
+// Spasi di awal teks (indentasi) dipertahankan di setiap baris hasilnya.
+func wrapText(text string, width int) []string {
+	text = asciiOnly(text)
+	if len(text) <= width {
+		return []string{text}
+	}
+	trimmed := strings.TrimLeft(text, " ")
+	if indent := len(text) - len(trimmed); indent > 0 && indent < width/2 {
+		lines := wrapText(trimmed, width-indent)
+		for i := range lines {
+			lines[i] = text[:indent] + lines[i]
+		}
+		return lines
+	}
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		for len(word) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	return append(lines, current)
+}
+
+// pairText menaruh label di kiri dan nominal rata kanan; label panjang dipindah ke baris sendiri
+func pairText(left, right string, width int) []string {
+	if len(left)+1+len(right) <= width {
+		return []string{left + strings.Repeat(" ", width-len(left)-len(right)) + right}
+	}
+	lines := wrapText(left, width)
+	last := lines[len(lines)-1]
+	if len(last)+1+len(right) <= width {
+		lines[len(lines)-1] = last + strings.Repeat(" ", width-len(last)-len(right)) + right
+		return lines
+	}
+	return append(lines, strings.Repeat(" ", max(width-len(right), 0))+right)
+}
+
+// asciiOnly mengganti karakter di luar ASCII karena font printer thermal tidak mendukungnya
+func asciiOnly(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r < 0x20 || r == 0x7f:
+			return -1
+		case r > 0x7e:
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+// formatRupiah formats an amount as 12.500 (atau 12.500,50 jika ada sen)
+func formatRupiah(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := fmt.Sprintf("%d", cents/100)
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	if cents%100 != 0 {
+		fmt.Fprintf(&b, ",%02d", cents%100)
+	}
+	return sign + b.String()
+}
+
+// formatRate menampilkan tarif tanpa nol di belakang, mis. 10 atau 2.5
+func formatRate(rate float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".")
+}
@@ -0,0 +1,49 @@
+package services
+
+// Perintah ESC/POS dasar yang didukung hampir semua printer thermal
+var (
+	escposInit      = []byte{0x1b, '@'}         // ESC @: reset printer
+	escposAlignLeft = []byte{0x1b, 'a', 0}      // ESC a 0
+	escposCenter    = []byte{0x1b, 'a', 1}      // ESC a 1
+	escposBoldOn    = []byte{0x1b, 'E', 1}      // ESC E 1
+	escposBoldOff   = []byte{0x1b, 'E', 0}      // ESC E 0
+	escposFeed      = []byte{0x1b, 'd', 4}      // ESC d 4: maju 4 baris sebelum dipotong
+	escposCut       = []byte{0x1d, 'V', 'B', 0} // GS V 66 0: potong kertas (partial cut)
+)
+
+// ESCPOS renders the receipt as raw ESC/POS commands that can be sent
+// directly to a thermal printer
+func (r Receipt) ESCPOS(paper ReceiptPaper) []byte {
+	out := append([]byte{}, escposInit...)
+
+	center, bold := false, false
+	for _, line := range r.Lines(paper) {
+		if line.Center != center {
+			center = line.Center
+			if center {
+				out = append(out, escposCenter...)
+			} else {
+				out = append(out, escposAlignLeft...)
+			}
+		}
+		if line.Bold != bold {
+			bold = line.Bold
+			if bold {
+				out = append(out, escposBoldOn...)
+			} else {
+				out = append(out, escposBoldOff...)
+			}
+		}
+		out = append(out, line.Text...)
+		out = append(out, '\n')
+	}
+
+	if bold {
+		out = append(out, escposBoldOff...)
+	}
+	if center {
+		out = append(out, escposAlignLeft...)
+	}
+	out = append(out, escposFeed...)
+	return append(out, escposCut...)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pdfMargin    = 8.0 // pt
+	pdfCharWidth = 0.6 // lebar karakter Courier per 1pt ukuran font
+	pdfLeading   = 1.25
+)
+
+// PDF renders the receipt as a single page PDF sized to the paper roll.
+// Memakai font standar Courier sehingga tidak perlu menyematkan font dan
+// tata letaknya sama persis dengan versi teks.
+func (r Receipt) PDF(paper ReceiptPaper) []byte {
+	lines := r.Lines(paper)
+	columns := paper.Columns()
+
+	pageWidth := float64(paper) * 72 / 25.4
+	fontSize := (pageWidth - 2*pdfMargin) / (float64(columns) * pdfCharWidth)
+	leading := fontSize * pdfLeading
+	pageHeight := 2*pdfMargin + float64(len(lines))*leading
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "BT\n%.2f TL\n%.2f %.2f Td\n", leading, pdfMargin, pageHeight-pdfMargin-fontSize)
+	font := ""
+	for _, line := range lines {
+		if f := pdfFont(line.Bold); f != font {
+			font = f
+			fmt.Fprintf(&content, "/%s %.2f Tf\n", font, fontSize)
+		}
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(padLine(line, columns)))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	// Tabel xref: setiap entri tepat 20 byte
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+func pdfFont(bold bool) string {
+	if bold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// pdfEscape meng-escape karakter khusus di dalam string literal PDF
+func pdfEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
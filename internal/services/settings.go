@@ -0,0 +1,47 @@
+package services
+
+import (
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Setting keys
+const (
+	SettingStockPolicy = "stock_policy"
+
+	// Identitas toko yang dicetak di struk
+	SettingStoreName     = "store_name"
+	SettingStoreAddress  = "store_address"
+	SettingReceiptFooter = "receipt_footer"
+)
+
+// GetSetting returns the value of a setting, or fallback if it is not set
+func GetSetting(db *gorm.DB, key, fallback string) string {
+	var setting models.Setting
+	if err := db.Where("key = ?", key).First(&setting).Error; err != nil {
+		return fallback
+	}
+	return setting.Value
+}
+
+// SetSetting creates or updates a setting
+func SetSetting(db *gorm.DB, key, value string) (models.Setting, error) {
+	setting := models.Setting{Key: key, Value: value}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"value": value, "updated_at": gorm.Expr("now()")}),
+	}).Create(&setting).Error
+	return setting, err
+}
+
+// DefaultStockPolicy returns the global insufficient stock policy.
+// Defaultnya "warn": penjualan tetap jalan tapi kasir diberi tahu.
+func DefaultStockPolicy(db *gorm.DB) models.StockPolicy {
+	policy := models.StockPolicy(GetSetting(db, SettingStockPolicy, string(models.StockPolicyWarn)))
+	if !policy.Valid() {
+		return models.StockPolicyWarn
+	}
+	return policy
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"sort"
+
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInventoryItemNotFound = errors.New("inventory item not found")
+
+// Shortage describes an ingredient that does not have enough stock for a sale
+type Shortage struct {
+	InventoryItemID uint               `json:"inventory_item_id"`
+	Name            string             `json:"name"`
+	Unit            string             `json:"unit"`
+	Required        float64            `json:"required"`
+	Available       float64            `json:"available"`
+	Short           float64            `json:"short"`
+	Policy          models.StockPolicy `json:"policy"`
+}
+
+// InsufficientStockError is returned when a shortage hits an item with the block policy
+type InsufficientStockError struct {
+	Shortages []Shortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	return "insufficient stock"
+}
+
+// MovementInfo describes why stock changed; it is written to the stock movement ledger
+type MovementInfo struct {
+	Type          models.StockMovementType
+	ReferenceType string
+	ReferenceID   *uint
+	UserID        *uint
+	Note          string
+}
+
+// StockChange is a relative change to the stock level of one inventory item.
+// UnitCost diisi untuk barang masuk dengan harga beli yang diketahui; cost_per_unit
+// item akan diperbarui dengan metode rata-rata tertimbang (moving weighted average).
+type StockChange struct {
+	InventoryItemID uint
+	Delta           float64
+	UnitCost        float64
+	MovementInfo
+}
+
+// StockService is the single place where InventoryItem.StockLevel is mutated.
+// Semua perubahan memakai UPDATE atomik (stock_level = stock_level + ?) atau
+// row lock, sehingga dua kasir yang menjual bersamaan tidak saling menimpa,
+// dan setiap perubahan dicatat di tabel stock_movements.
+type StockService struct {
+	DB *gorm.DB
+}
+
+// NewStockService creates a stock service on the given handle.
+// Berikan tx jika perubahan stok harus ikut transaksi yang sedang berjalan.
+func NewStockService(db *gorm.DB) *StockService {
+	return &StockService{DB: db}
+}
+
+// Apply applies the changes atomically and returns the updated items.
+// Perubahan untuk item yang sama digabung dan dijalankan berurutan berdasarkan ID
+// supaya transaksi yang berjalan bersamaan tidak deadlock.
+func (s *StockService) Apply(changes []StockChange) ([]models.InventoryItem, error) {
+	merged := mergeStockChanges(changes)
+	if len(merged) == 0 {
+		return nil, nil
+	}
+
+	items := make([]models.InventoryItem, 0, len(merged))
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, change := range merged {
+			updates := map[string]interface{}{
+				"stock_level": gorm.Expr("stock_level + ?", change.Delta),
+				"updated_at":  gorm.Expr("now()"),
+			}
+			if change.Delta > 0 && change.UnitCost > 0 {
+				// Nilai kolom di sisi kanan adalah nilai lama (sebelum UPDATE)
+				updates["cost_per_unit"] = gorm.Expr(
+					"CASE WHEN stock_level <= 0 THEN ? ELSE (stock_level * cost_per_unit + ? * ?) / (stock_level + ?) END",
+					change.UnitCost, change.Delta, change.UnitCost, change.Delta,
+				)
+			}
+
+			var item models.InventoryItem
+			result := tx.Model(&item).
+				Clauses(clause.Returning{}).
+				Where("id = ?", change.InventoryItemID).
+				Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrInventoryItemNotFound
+			}
+			if err := recordMovement(tx, item, change.Delta, change.MovementInfo); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetLevel overwrites the stock level of an item (manual edit) and returns
+// the item together with the previous level. Row di-lock selama perubahan dan
+// selisihnya dicatat sebagai movement.
+func (s *StockService) SetLevel(itemID uint, level float64, info MovementInfo) (item models.InventoryItem, previous float64, err error) {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, itemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInventoryItemNotFound
+			}
+			return err
+		}
+
+		previous = item.StockLevel
+		if previous == level {
+			return nil
+		}
+		item.StockLevel = level
+		if err := tx.Model(&item).Update("stock_level", level).Error; err != nil {
+			return err
+		}
+		return recordMovement(tx, item, level-previous, info)
+	})
+	return item, previous, err
+}
+
+// RecordOpeningBalance records the initial stock of a newly created item
+func (s *StockService) RecordOpeningBalance(item models.InventoryItem, info MovementInfo) error {
+	if item.StockLevel == 0 {
+		return nil
+	}
+	return recordMovement(s.DB, item, item.StockLevel, info)
+}
+
+// CheckShortages locks the items that would be reduced by changes and reports
+// which of them would drop below zero, together with their effective policy.
+// Item dengan policy "allow" tidak dilaporkan. Jika ada item dengan policy "block",
+// error berupa *InsufficientStockError berisi semua kekurangan.
+func (s *StockService) CheckShortages(changes []StockChange, defaultPolicy models.StockPolicy) ([]Shortage, error) {
+	var shortages []Shortage
+	var blocked bool
+
+	// Jumlahkan kebutuhan per item, terlepas dari jenis movement-nya
+	var itemIDs []uint
+	totals := make(map[uint]float64)
+	for _, change := range mergeStockChanges(changes) {
+		if _, ok := totals[change.InventoryItemID]; !ok {
+			itemIDs = append(itemIDs, change.InventoryItemID)
+		}
+		totals[change.InventoryItemID] += change.Delta
+	}
+
+	for _, itemID := range itemIDs {
+		delta := totals[itemID]
+		if delta >= 0 {
+			continue
+		}
+
+		var item models.InventoryItem
+		if err := s.DB.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, itemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrInventoryItemNotFound
+			}
+			return nil, err
+		}
+
+		required := -delta
+		if item.StockLevel >= required {
+			continue
+		}
+
+		policy := item.StockPolicy
+		if !policy.Valid() {
+			policy = defaultPolicy
+		}
+		if policy == models.StockPolicyAllow {
+			continue
+		}
+		if policy == models.StockPolicyBlock {
+			blocked = true
+		}
+
+		shortages = append(shortages, Shortage{
+			InventoryItemID: item.ID,
+			Name:            item.Name,
+			Unit:            item.Unit,
+			Required:        required,
+			Available:       item.StockLevel,
+			Short:           required - item.StockLevel,
+			Policy:          policy,
+		})
+	}
+
+	if blocked {
+		return shortages, &InsufficientStockError{Shortages: shortages}
+	}
+	return shortages, nil
+}
+
+// RecipeChanges returns the stock changes for selling (sign -1) or returning
+// (sign +1) quantity units of a product, based on its recipe
+func (s *StockService) RecipeChanges(productID uint, quantity int, sign float64, info MovementInfo) ([]StockChange, error) {
+	var recipeItems []models.RecipeItem
+	if err := s.DB.Where("product_id = ?", productID).Find(&recipeItems).Error; err != nil {
+		return nil, err
+	}
+
+	changes := make([]StockChange, 0, len(recipeItems))
+	for _, recipeItem := range recipeItems {
+		changes = append(changes, StockChange{
+			InventoryItemID: recipeItem.InventoryItemID,
+			Delta:           sign * recipeItem.QuantityUsed * float64(quantity),
+			MovementInfo:    info,
+		})
+	}
+	return changes, nil
+}
+
+// ModifierChanges returns the stock changes for the extra ingredients of the
+// given modifier options, the same way RecipeChanges does for a product
+func (s *StockService) ModifierChanges(optionIDs []uint, quantity int, sign float64, info MovementInfo) ([]StockChange, error) {
+	if len(optionIDs) == 0 {
+		return nil, nil
+	}
+
+	var recipeItems []models.ModifierRecipeItem
+	if err := s.DB.Where("modifier_option_id IN ?", optionIDs).Find(&recipeItems).Error; err != nil {
+		return nil, err
+	}
+
+	changes := make([]StockChange, 0, len(recipeItems))
+	for _, recipeItem := range recipeItems {
+		changes = append(changes, StockChange{
+			InventoryItemID: recipeItem.InventoryItemID,
+			Delta:           sign * recipeItem.QuantityUsed * float64(quantity),
+			MovementInfo:    info,
+		})
+	}
+	return changes, nil
+}
+
+// mergeStockChanges menggabungkan perubahan per item (dan per jenis/referensi movement)
+// lalu mengurutkannya berdasarkan ID item
+func mergeStockChanges(changes []StockChange) []StockChange {
+	type mergeKey struct {
+		itemID        uint
+		unitCost      float64
+		movementType  models.StockMovementType
+		referenceType string
+		referenceID   uint
+	}
+
+	var merged []StockChange
+	index := make(map[mergeKey]int, len(changes))
+	for _, change := range changes {
+		key := mergeKey{change.InventoryItemID, change.UnitCost, change.Type, change.ReferenceType, 0}
+		if change.ReferenceID != nil {
+			key.referenceID = *change.ReferenceID
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Delta += change.Delta
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, change)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].InventoryItemID < merged[j].InventoryItemID
+	})
+	return merged
+}
+
+// recordMovement menulis satu baris ledger dengan saldo setelah perubahan
+func recordMovement(tx *gorm.DB, item models.InventoryItem, quantity float64, info MovementInfo) error {
+	if quantity == 0 {
+		return nil
+	}
+	movementType := info.Type
+	if movementType == "" {
+		movementType = models.StockMovementAdjustment
+	}
+	return tx.Create(&models.StockMovement{
+		InventoryItemID: item.ID,
+		Type:            movementType,
+		Quantity:        quantity,
+		BalanceAfter:    item.StockLevel,
+		ReferenceType:   info.ReferenceType,
+		ReferenceID:     info.ReferenceID,
+		UserID:          info.UserID,
+		Note:            info.Note,
+	}).Error
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"hayoon-bite-backend/internal/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the database in TEST_DATABASE_URL, which must already be
+// migrated (go run ./cmd/migrate up, lihat bagian Tests di README). Test dilewati
+// jika variabel tidak diisi.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+	return db
+}
+
+// TestApplyConcurrentNoLostUpdates sells one unit of the same item from many
+// transactions at once; setiap penjualan harus mengurangi stok dan tercatat di ledger.
+func TestApplyConcurrentNoLostUpdates(t *testing.T) {
+	db := openTestDB(t)
+
+	const (
+		start   = 100.0
+		workers = 25
+	)
+	item := models.InventoryItem{
+		Name:       fmt.Sprintf("test-concurrent-%d", time.Now().UnixNano()),
+		StockLevel: start,
+		Unit:       "pcs",
+	}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("inventory_item_id = ?", item.ID).Delete(&models.StockMovement{})
+		db.Delete(&item)
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	ready := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ready
+			errs <- db.Transaction(func(tx *gorm.DB) error {
+				_, err := NewStockService(tx).Apply([]StockChange{{
+					InventoryItemID: item.ID,
+					Delta:           -1,
+					MovementInfo:    MovementInfo{Type: models.StockMovementSale},
+				}})
+				return err
+			})
+		}()
+	}
+	close(ready)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+
+	var updated models.InventoryItem
+	if err := db.First(&updated, item.ID).Error; err != nil {
+		t.Fatalf("reload item: %v", err)
+	}
+	if want := start - workers; updated.StockLevel != want {
+		t.Errorf("stock_level = %v, want %v", updated.StockLevel, want)
+	}
+
+	var movements int64
+	if err := db.Model(&models.StockMovement{}).Where("inventory_item_id = ?", item.ID).Count(&movements).Error; err != nil {
+		t.Fatalf("count movements: %v", err)
+	}
+	if movements != workers {
+		t.Errorf("stock_movements = %d, want %d", movements, workers)
+	}
+}
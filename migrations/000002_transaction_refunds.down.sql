@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE transaction_items
+    DROP COLUMN IF EXISTS refunded_quantity;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS void_reason,
+    DROP COLUMN IF EXISTS voided_by_id,
+    DROP COLUMN IF EXISTS voided_at,
+    DROP COLUMN IF EXISTS refunded_amount,
+    DROP COLUMN IF EXISTS status;
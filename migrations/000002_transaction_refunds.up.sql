@@ -0,0 +1,33 @@
+-- 1. Status & void columns on transactions
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed',
+    ADD COLUMN IF NOT EXISTS refunded_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS voided_by_id INT REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+ALTER TABLE transaction_items
+    ADD COLUMN IF NOT EXISTS refunded_quantity INT NOT NULL DEFAULT 0;
+
+-- 2. Create refunds table
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 3. Create refund_items table
+CREATE TABLE IF NOT EXISTS refund_items (
+    id SERIAL PRIMARY KEY,
+    refund_id INT NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    transaction_item_id INT NOT NULL REFERENCES transaction_items(id),
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_transaction_id ON refunds(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refund_items_refund_id ON refund_items(refund_id);
@@ -0,0 +1,7 @@
+ALTER TABLE transaction_items
+    DROP COLUMN IF EXISTS unit_cost,
+    DROP COLUMN IF EXISTS unit_price,
+    DROP COLUMN IF EXISTS product_name;
+
+ALTER TABLE inventory_items
+    DROP COLUMN IF EXISTS cost_per_unit;
@@ -0,0 +1,17 @@
+-- 1. Cost per unit for inventory items (basis for recipe cost). Harga per gram/ml
+-- sering pecahan rupiah, jadi simpan 4 desimal supaya unit_cost tidak jadi 0.00
+ALTER TABLE inventory_items
+    ADD COLUMN IF NOT EXISTS cost_per_unit NUMERIC(14, 4) NOT NULL DEFAULT 0;
+
+-- 2. Price & cost snapshot on transaction_items
+ALTER TABLE transaction_items
+    ADD COLUMN IF NOT EXISTS product_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS unit_price NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS unit_cost NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- 3. Backfill existing rows from what we know
+UPDATE transaction_items ti
+SET product_name = p.name,
+    unit_price = CASE WHEN ti.quantity > 0 THEN ti.subtotal / ti.quantity ELSE 0 END
+FROM products p
+WHERE p.id = ti.product_id AND ti.product_name = '';
@@ -0,0 +1,9 @@
+ALTER TABLE refunds
+    DROP COLUMN IF EXISTS shift_id;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS shift_id,
+    DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS shift_payments;
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,41 @@
+-- 1. Create shifts table
+CREATE TABLE IF NOT EXISTS shifts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opening_cash NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    expected_cash NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    counted_cash NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    cash_variance NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    closed_by_id INT REFERENCES users(id),
+    closed_at TIMESTAMPTZ,
+    notes TEXT,
+    opened_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Satu kasir hanya boleh punya satu shift yang terbuka
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open_user ON shifts(user_id) WHERE status = 'open';
+
+-- 2. Create shift_payments table
+CREATE TABLE IF NOT EXISTS shift_payments (
+    id SERIAL PRIMARY KEY,
+    shift_id INT NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    payment_method VARCHAR(50) NOT NULL,
+    expected_amount NUMERIC(12, 2) NOT NULL,
+    counted_amount NUMERIC(12, 2) NOT NULL,
+    variance NUMERIC(12, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_payments_shift_id ON shift_payments(shift_id);
+
+-- 3. Link transactions and refunds to cashier & shift
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS user_id INT REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id);
+
+ALTER TABLE refunds
+    ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES shifts(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions(shift_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_shift_id ON refunds(shift_id);
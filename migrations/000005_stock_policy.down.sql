@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS settings;
+
+ALTER TABLE inventory_items
+    DROP COLUMN IF EXISTS stock_policy;
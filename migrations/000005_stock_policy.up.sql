@@ -0,0 +1,10 @@
+-- 1. Per-item insufficient stock policy ('' = follow global setting)
+ALTER TABLE inventory_items
+    ADD COLUMN IF NOT EXISTS stock_policy VARCHAR(10) NOT NULL DEFAULT '';
+
+-- 2. Create settings table
+CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,62 @@
+-- 1. Create suppliers table
+CREATE TABLE IF NOT EXISTS suppliers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    address TEXT,
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. Create purchase_orders table
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id SERIAL PRIMARY KEY,
+    supplier_id INT NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'ordered',
+    order_date TIMESTAMPTZ NOT NULL,
+    notes TEXT,
+    total_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    received_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    created_by_id INT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 3. Create purchase_order_lines table
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    inventory_item_id INT NOT NULL REFERENCES inventory_items(id) ON DELETE RESTRICT,
+    quantity_ordered NUMERIC(12, 2) NOT NULL,
+    quantity_received NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    unit_price NUMERIC(12, 2) NOT NULL
+);
+
+-- 4. Create purchase_receipts table
+CREATE TABLE IF NOT EXISTS purchase_receipts (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INT NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    invoice_number VARCHAR(100),
+    amount NUMERIC(12, 2) NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id),
+    notes TEXT,
+    received_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 5. Create purchase_receipt_items table
+CREATE TABLE IF NOT EXISTS purchase_receipt_items (
+    id SERIAL PRIMARY KEY,
+    purchase_receipt_id INT NOT NULL REFERENCES purchase_receipts(id) ON DELETE CASCADE,
+    purchase_order_line_id INT NOT NULL REFERENCES purchase_order_lines(id),
+    inventory_item_id INT NOT NULL REFERENCES inventory_items(id),
+    quantity NUMERIC(12, 2) NOT NULL,
+    unit_price NUMERIC(12, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_supplier_id ON purchase_orders(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_lines_purchase_order_id ON purchase_order_lines(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_purchase_order_id ON purchase_receipts(purchase_order_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_receipts_received_at ON purchase_receipts(received_at);
+CREATE INDEX IF NOT EXISTS idx_purchase_receipt_items_purchase_receipt_id ON purchase_receipt_items(purchase_receipt_id);
@@ -0,0 +1,2 @@
+ALTER TABLE inventory_items
+    ALTER COLUMN cost_per_unit TYPE NUMERIC(12, 2);
@@ -0,0 +1,5 @@
+-- Cost per unit is often a fraction of a rupiah per gram/ml, keep 4 decimals
+-- for the moving weighted average. Database baru sudah NUMERIC(14, 4) dari 000003;
+-- ini untuk database yang menjalankan 000003 versi lama (NUMERIC(12, 2))
+ALTER TABLE inventory_items
+    ALTER COLUMN cost_per_unit TYPE NUMERIC(14, 4);
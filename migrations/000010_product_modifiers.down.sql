@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transaction_item_modifiers;
+DROP TABLE IF EXISTS modifier_recipe_items;
+DROP TABLE IF EXISTS modifier_options;
+DROP TABLE IF EXISTS modifier_groups;
@@ -0,0 +1,41 @@
+-- 1. Create modifier_groups table
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    min_select INT NOT NULL DEFAULT 0,
+    max_select INT NOT NULL DEFAULT 0,
+    display_order INT NOT NULL DEFAULT 0
+);
+
+-- 2. Create modifier_options table
+CREATE TABLE IF NOT EXISTS modifier_options (
+    id SERIAL PRIMARY KEY,
+    modifier_group_id INT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    price_delta NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    display_order INT NOT NULL DEFAULT 0
+);
+
+-- 3. Create modifier_recipe_items table (extra ingredients per option)
+CREATE TABLE IF NOT EXISTS modifier_recipe_items (
+    id SERIAL PRIMARY KEY,
+    modifier_option_id INT NOT NULL REFERENCES modifier_options(id) ON DELETE CASCADE,
+    inventory_item_id INT NOT NULL REFERENCES inventory_items(id) ON DELETE RESTRICT,
+    quantity_used NUMERIC(10, 2) NOT NULL
+);
+
+-- 4. Create transaction_item_modifiers table (snapshot of chosen options)
+CREATE TABLE IF NOT EXISTS transaction_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    transaction_item_id INT NOT NULL REFERENCES transaction_items(id) ON DELETE CASCADE,
+    modifier_option_id INT REFERENCES modifier_options(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    price_delta NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    unit_cost NUMERIC(12, 2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_product_id ON modifier_groups(product_id);
+CREATE INDEX IF NOT EXISTS idx_modifier_options_modifier_group_id ON modifier_options(modifier_group_id);
+CREATE INDEX IF NOT EXISTS idx_modifier_recipe_items_modifier_option_id ON modifier_recipe_items(modifier_option_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_item_modifiers_transaction_item_id ON transaction_item_modifiers(transaction_item_id);
@@ -0,0 +1,9 @@
+ALTER TABLE transaction_items
+    DROP COLUMN IF EXISTS discount_amount;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS discount_amount;
+
+DROP TABLE IF EXISTS transaction_discounts;
+DROP TABLE IF EXISTS vouchers;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,55 @@
+-- 1. Create promotions table
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    value NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    scope VARCHAR(20) NOT NULL DEFAULT 'order',
+    product_id INT REFERENCES products(id) ON DELETE CASCADE,
+    category_id INT REFERENCES product_categories(id) ON DELETE CASCADE,
+    min_subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    auto_apply BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    daily_start_time VARCHAR(5) NOT NULL DEFAULT '',
+    daily_end_time VARCHAR(5) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. Create vouchers table
+CREATE TABLE IF NOT EXISTS vouchers (
+    id SERIAL PRIMARY KEY,
+    promotion_id INT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    max_uses INT NOT NULL DEFAULT 1,
+    used_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 3. Create transaction_discounts table
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    promotion_id INT REFERENCES promotions(id) ON DELETE SET NULL,
+    voucher_id INT REFERENCES vouchers(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50),
+    amount NUMERIC(12, 2) NOT NULL
+);
+
+-- 4. Discount totals on transactions and their items
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS discount_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_items
+    ADD COLUMN IF NOT EXISTS discount_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_vouchers_promotion_id ON vouchers(promotion_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_transaction_id ON transaction_discounts(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_promotion_id ON transaction_discounts(promotion_id);
@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS service_charge_amount,
+    DROP COLUMN IF EXISTS subtotal;
+
+DROP TABLE IF EXISTS transaction_charges;
+DROP TABLE IF EXISTS tax_rules;
@@ -0,0 +1,38 @@
+-- 1. Create tax_rules table (tax and service charge configuration)
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    rate NUMERIC(5, 2) NOT NULL,
+    inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. Create transaction_charges table (snapshot of applied rules)
+CREATE TABLE IF NOT EXISTS transaction_charges (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tax_rule_id INT REFERENCES tax_rules(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    rate NUMERIC(5, 2) NOT NULL,
+    inclusive BOOLEAN NOT NULL,
+    base NUMERIC(12, 2) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL
+);
+
+-- 3. Subtotal, service charge and tax totals on transactions
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS subtotal NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS service_charge_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+-- 4. Backfill: existing transactions had no tax or service charge
+UPDATE transactions
+SET subtotal = total_amount + discount_amount
+WHERE subtotal = 0;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_charges_transaction_id ON transaction_charges(transaction_id);
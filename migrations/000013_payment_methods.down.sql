@@ -0,0 +1,5 @@
+ALTER TABLE refunds
+    DROP COLUMN IF EXISTS payment_method;
+
+DROP TABLE IF EXISTS transaction_payments;
+DROP TABLE IF EXISTS payment_methods;
@@ -0,0 +1,61 @@
+-- 1. Create payment_methods table (managed list instead of free text)
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    is_cash BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    display_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO payment_methods (code, name, is_cash, display_order) VALUES
+    ('cash', 'Tunai', TRUE, 1),
+    ('qris', 'QRIS', FALSE, 2),
+    ('debit', 'Kartu Debit', FALSE, 3),
+    ('transfer', 'Transfer Bank', FALSE, 4)
+ON CONFLICT (code) DO NOTHING;
+
+-- 2. Normalize existing free-text payment methods to codes
+UPDATE transactions
+SET payment_method = CASE
+    WHEN lower(trim(payment_method)) IN ('cash', 'tunai') THEN 'cash'
+    ELSE lower(trim(payment_method))
+END;
+
+INSERT INTO payment_methods (code, name, display_order)
+SELECT DISTINCT payment_method, initcap(payment_method), 99
+FROM transactions
+WHERE payment_method <> ''
+ON CONFLICT (code) DO NOTHING;
+
+-- 3. Create transaction_payments table
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    payment_method_id INT NOT NULL REFERENCES payment_methods(id) ON DELETE RESTRICT,
+    payment_method VARCHAR(50) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    tendered NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    change_amount NUMERIC(12, 2) NOT NULL DEFAULT 0
+);
+
+-- 4. Backfill one payment line for every existing transaction
+INSERT INTO transaction_payments (transaction_id, payment_method_id, payment_method, amount, tendered)
+SELECT t.id, pm.id, pm.code, t.total_amount, t.total_amount
+FROM transactions t
+JOIN payment_methods pm ON pm.code = t.payment_method
+WHERE NOT EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id);
+
+-- 5. Refunds record the method the money was returned with
+ALTER TABLE refunds
+    ADD COLUMN IF NOT EXISTS payment_method VARCHAR(50) NOT NULL DEFAULT '';
+
+UPDATE refunds r
+SET payment_method = t.payment_method
+FROM transactions t
+WHERE t.id = r.transaction_id AND r.payment_method = '';
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_payment_method ON transaction_payments(payment_method);
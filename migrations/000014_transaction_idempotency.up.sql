@@ -0,0 +1,6 @@
+-- Idempotency key sent by the POS client, so a retried checkout is not recorded twice.
+-- NULL untuk transaksi lama / klien yang tidak mengirim key (unique index mengizinkan banyak NULL).
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(100);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_idempotency_key ON transactions(idempotency_key);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS order_queue_counters;
+
+DROP INDEX IF EXISTS idx_transactions_active_orders;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS picked_up_at,
+    DROP COLUMN IF EXISTS ready_at,
+    DROP COLUMN IF EXISTS preparing_at,
+    DROP COLUMN IF EXISTS queue_number,
+    DROP COLUMN IF EXISTS order_status;
@@ -0,0 +1,22 @@
+-- 1. Kitchen order status and customer queue number on transactions.
+-- Transaksi lama dianggap sudah diambil supaya tidak muncul di layar dapur.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS order_status VARCHAR(20) NOT NULL DEFAULT 'picked_up',
+    ADD COLUMN IF NOT EXISTS queue_number INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS preparing_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS ready_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMPTZ;
+
+ALTER TABLE transactions
+    ALTER COLUMN order_status SET DEFAULT 'queued';
+
+-- Layar dapur hanya membaca pesanan yang belum diambil
+CREATE INDEX IF NOT EXISTS idx_transactions_active_orders
+    ON transactions(order_status, transaction_time)
+    WHERE order_status <> 'picked_up';
+
+-- 2. Daily queue number counter (nomor antrean mulai dari 1 setiap hari)
+CREATE TABLE IF NOT EXISTS order_queue_counters (
+    queue_date DATE PRIMARY KEY,
+    last_number INT NOT NULL DEFAULT 0
+);
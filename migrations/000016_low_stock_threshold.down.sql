@@ -0,0 +1,2 @@
+ALTER TABLE inventory_items
+    DROP COLUMN IF EXISTS low_stock_threshold;
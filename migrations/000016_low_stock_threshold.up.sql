@@ -0,0 +1,4 @@
+-- Low stock threshold per inventory item, used for live low stock notifications.
+-- 0 berarti notifikasi dimatikan untuk item tersebut.
+ALTER TABLE inventory_items
+    ADD COLUMN IF NOT EXISTS low_stock_threshold NUMERIC(10, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS online_order_item_modifiers;
+DROP TABLE IF EXISTS online_order_items;
+DROP TABLE IF EXISTS online_orders;
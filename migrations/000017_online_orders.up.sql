@@ -0,0 +1,40 @@
+-- 1. Create online_orders table (pickup orders from the website)
+CREATE TABLE IF NOT EXISTS online_orders (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    customer_name VARCHAR(100) NOT NULL,
+    customer_phone VARCHAR(30) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    pickup_time TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    estimated_total NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    reviewed_by_id INT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    reject_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. Create online_order_items table
+CREATE TABLE IF NOT EXISTS online_order_items (
+    id SERIAL PRIMARY KEY,
+    online_order_id INT NOT NULL REFERENCES online_orders(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    product_name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL,
+    unit_price NUMERIC(12, 2) NOT NULL
+);
+
+-- 3. Create online_order_item_modifiers table
+CREATE TABLE IF NOT EXISTS online_order_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    online_order_item_id INT NOT NULL REFERENCES online_order_items(id) ON DELETE CASCADE,
+    modifier_option_id INT REFERENCES modifier_options(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    price_delta NUMERIC(12, 2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_online_orders_status ON online_orders(status, pickup_time);
+CREATE INDEX IF NOT EXISTS idx_online_order_items_online_order_id ON online_order_items(online_order_id);
+CREATE INDEX IF NOT EXISTS idx_online_order_item_modifiers_item_id ON online_order_item_modifiers(online_order_item_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_transactions_customer_id;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS points_redeemed,
+    DROP COLUMN IF EXISTS points_earned,
+    DROP COLUMN IF EXISTS customer_id;
+
+DROP TABLE IF EXISTS loyalty_point_entries;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,33 @@
+-- 1. Create customers table (kunci unik: nomor telepon yang dinormalisasi)
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    phone VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    points_balance INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 2. Create loyalty_point_entries table (riwayat perubahan saldo poin)
+CREATE TABLE IF NOT EXISTS loyalty_point_entries (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL,
+    points INT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_customer_id ON loyalty_point_entries(customer_id);
+CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_transaction_id ON loyalty_point_entries(transaction_id);
+
+-- 3. Link transactions to customers
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS points_earned INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS points_redeemed INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_id ON transactions(customer_id);
@@ -0,0 +1,33 @@
+-- 1. Create stock_counts table (sesi stock opname)
+CREATE TABLE IF NOT EXISTS stock_counts (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    notes TEXT NOT NULL DEFAULT '',
+    started_by_id INT NOT NULL REFERENCES users(id),
+    started_at TIMESTAMPTZ DEFAULT NOW(),
+    closed_by_id INT REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMPTZ
+);
+
+-- Hanya boleh ada satu stock opname yang sedang berjalan
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_counts_in_progress ON stock_counts(status) WHERE status = 'in_progress';
+
+-- 2. Create stock_count_items table
+CREATE TABLE IF NOT EXISTS stock_count_items (
+    id SERIAL PRIMARY KEY,
+    stock_count_id INT NOT NULL REFERENCES stock_counts(id) ON DELETE CASCADE,
+    inventory_item_id INT NOT NULL REFERENCES inventory_items(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    system_quantity NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    counted_quantity NUMERIC(10, 2),
+    variance NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    cost_per_unit NUMERIC(14, 4) NOT NULL DEFAULT 0,
+    variance_value NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    counted_by_id INT REFERENCES users(id) ON DELETE SET NULL,
+    counted_at TIMESTAMPTZ,
+    UNIQUE (stock_count_id, inventory_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_count_items_stock_count_id ON stock_count_items(stock_count_id);
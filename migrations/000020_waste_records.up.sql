@@ -0,0 +1,18 @@
+-- Waste & spoilage: bahan baku atau produk jadi yang dibuang
+CREATE TABLE IF NOT EXISTS waste_records (
+    id SERIAL PRIMARY KEY,
+    inventory_item_id INT REFERENCES inventory_items(id) ON DELETE SET NULL,
+    product_id INT REFERENCES products(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    quantity NUMERIC(10, 2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    cost_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    user_id INT NOT NULL REFERENCES users(id),
+    recorded_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_waste_records_recorded_at ON waste_records(recorded_at);
+CREATE INDEX IF NOT EXISTS idx_waste_records_inventory_item_id ON waste_records(inventory_item_id);
+CREATE INDEX IF NOT EXISTS idx_waste_records_product_id ON waste_records(product_id);
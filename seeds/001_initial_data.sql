@@ -0,0 +1,46 @@
+-- Initial data for a fresh database.
+-- File ini dijalankan oleh `go run ./cmd/migrate seed` dan aman dijalankan berulang kali.
+
+-- 1. Insert initial data (Inventory Items)
+INSERT INTO inventory_items (name, stock_level, unit) VALUES
+('Roti Hotdog', 100, 'pcs'),
+('Mentega', 1000, 'gram'),
+('Selai Coklat', 1000, 'gram'),
+('Susu Kental Manis', 500, 'ml'),
+('Kantong Kresek', 500, 'pcs')
+ON CONFLICT (name) DO NOTHING;
+
+-- 2. Insert sample product (UPDATE: Added image_path value)
+INSERT INTO products (name, price, image_path) VALUES
+('Roti Coklat', 6000.00, NULL) -- <--- ISI NULL UNTUK PRODUK AWAL
+ON CONFLICT (name) DO NOTHING;
+
+-- 3. Insert recipe for Roti Coklat (FIXED AND ROBUST)
+WITH product_id_cte AS (
+    SELECT id FROM products WHERE name = 'Roti Coklat'
+)
+INSERT INTO recipe_items (product_id, inventory_item_id, quantity_used)
+SELECT
+    (SELECT id FROM product_id_cte), 
+    ii.id,                           
+    CASE 
+        WHEN ii.name = 'Roti Hotdog' THEN 1.00
+        WHEN ii.name = 'Mentega' THEN 10.00
+        WHEN ii.name = 'Selai Coklat' THEN 15.00
+        WHEN ii.name = 'Susu Kental Manis' THEN 5.00
+        WHEN ii.name = 'Kantong Kresek' THEN 1.00
+    END
+FROM inventory_items ii
+WHERE ii.name IN ('Roti Hotdog', 'Mentega', 'Selai Coklat', 'Susu Kental Manis', 'Kantong Kresek')
+  AND EXISTS (SELECT 1 FROM product_id_cte)
+  -- recipe_items tidak punya unique constraint, jadi cek manual supaya tidak dobel
+  AND NOT EXISTS (
+    SELECT 1 FROM recipe_items ri
+    WHERE ri.product_id = (SELECT id FROM product_id_cte) AND ri.inventory_item_id = ii.id
+  );
+
+-- 4. Create default admin user (password: admin123)
+-- Note: The hash is for 'password', but we will use it as provided.
+INSERT INTO users (username, password_hash, role) VALUES
+('admin', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'admin')
+ON CONFLICT (username) DO NOTHING;
\ No newline at end of file